@@ -0,0 +1,12 @@
+package main
+
+import (
+	"github.com/hareku/emosearch-api/pkg/interfaces/lambda/job"
+	"github.com/hareku/emosearch-api/pkg/registry"
+)
+
+func main() {
+	registry := registry.NewRegistry()
+	handler := job.New(registry)
+	handler.StartBackfillTweets()
+}
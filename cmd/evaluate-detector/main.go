@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
+	"github.com/hareku/emosearch-api/pkg/domain/twitter"
+	"github.com/hareku/emosearch-api/pkg/infrastructure/dataset"
+	"github.com/hareku/emosearch-api/pkg/registry"
+)
+
+// It evaluates sentiment detectors on a labeled dataset, and exits with 1 if any detector is below -min-accuracy.
+func main() {
+	datasetPath := flag.String("dataset", "", "labeled dataset file (.csv with text and label columns, or .jsonl)")
+	detectors := flag.String("detectors", string(sentiment.DetectorBayes), "comma separated sentiment detectors to evaluate")
+	batchSize := flag.Int("batch-size", 25, "number of texts per request")
+	minAccuracy := flag.Float64("min-accuracy", 0, "minimum accuracy which every detector must reach")
+	jsonOutput := flag.Bool("json", false, "print reports as JSON")
+	preprocessTexts := flag.Bool("preprocess", true, "preprocess texts like collected tweets")
+	flag.Parse()
+
+	if *datasetPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	texts, err := dataset.LoadLabeledTexts(*datasetPath)
+	if err != nil {
+		log.Fatalf("Failed to load dataset: %s", err)
+	}
+
+	registry := registry.NewRegistry()
+	if *preprocessTexts {
+		preprocessor := registry.NewTextPreprocessor()
+		for i := range texts {
+			texts[i].Text = preprocessor.Preprocess(texts[i].Text, twitter.Entities{})
+		}
+	}
+
+	names := []sentiment.DetectorName{}
+	for _, name := range strings.Split(*detectors, ",") {
+		names = append(names, sentiment.DetectorName(strings.TrimSpace(name)))
+	}
+
+	provider := registry.NewSentimentDetectorProvider()
+	reports := map[sentiment.DetectorName]*sentiment.Evaluation{}
+	passed := true
+
+	for _, name := range names {
+		detector, err := provider.Detector(name)
+		if err != nil {
+			log.Fatalf("Failed to select sentiment detector: %s", err)
+		}
+
+		report, err := sentiment.Evaluate(context.Background(), detector, texts, *batchSize)
+		if err != nil {
+			log.Fatalf("Failed to evaluate %s: %s", name, err)
+		}
+		reports[name] = report
+
+		if report.Accuracy < *minAccuracy {
+			log.Printf("Accuracy of %s is %.4f, which is below %.4f.\n", name, report.Accuracy, *minAccuracy)
+			passed = false
+		}
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(reports); err != nil {
+			log.Fatalf("Failed to print reports: %s", err)
+		}
+	} else {
+		for _, name := range names {
+			printReport(name, reports[name])
+		}
+	}
+
+	if !passed {
+		os.Exit(1)
+	}
+}
+
+func printReport(name sentiment.DetectorName, report *sentiment.Evaluation) {
+	fmt.Printf("== %s ==\n", name)
+	fmt.Printf("texts: %d, correct: %d, failed: %d\n", report.Total, report.Correct, report.Failed)
+	fmt.Printf("accuracy: %.4f, macro F1: %.4f\n", report.Accuracy, report.MacroF1)
+	fmt.Printf("latency: total %s, per text %s, batch p50 %s, batch p95 %s\n\n",
+		report.Latency.Total, report.Latency.PerText, report.Latency.P50, report.Latency.P95)
+
+	labels := []sentiment.Label{}
+	for label := range report.Labels {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i] < labels[j] })
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "label\tprecision\trecall\tF1\tsupport")
+	for _, label := range labels {
+		m := report.Labels[label]
+		fmt.Fprintf(w, "%s\t%.4f\t%.4f\t%.4f\t%d\n", label, m.Precision, m.Recall, m.F1, m.Support)
+	}
+	w.Flush()
+	fmt.Println()
+
+	// Rows are gold labels, and columns are predicted labels.
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "gold \\ predicted")
+	for _, label := range labels {
+		fmt.Fprintf(w, "\t%s", label)
+	}
+	fmt.Fprintln(w)
+	for _, gold := range labels {
+		fmt.Fprintf(w, "%s", gold)
+		for _, predicted := range labels {
+			fmt.Fprintf(w, "\t%d", report.Confusion[gold][predicted])
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+	fmt.Println()
+}
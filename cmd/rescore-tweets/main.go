@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
+	"github.com/hareku/emosearch-api/pkg/interfaces/lambda/job"
+	"github.com/hareku/emosearch-api/pkg/registry"
+)
+
+// It runs as the Lambda function in AWS Lambda, and as the command with flags in the others.
+func main() {
+	registry := registry.NewRegistry()
+
+	if os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "" {
+		handler := job.New(registry)
+		handler.StartRescoreTweets()
+		return
+	}
+
+	searchID := flag.String("search-id", "", "ID of the search whose tweets are re-scored")
+	userID := flag.String("user-id", "", "ID of the user who owns the search")
+	detector := flag.String("detector", "", "sentiment detector (bayes, comprehend, lexicon or ensemble), defaults to the detector of the search")
+	dryRun := flag.Bool("dry-run", false, "report label changes without updating tweets")
+	flag.Parse()
+
+	if *searchID == "" || *userID == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	report, err := registry.NewBatchUsecase().RescoreTweets(context.Background(), &model.TweetRescoring{
+		SearchID: model.SearchID(*searchID),
+		UserID:   model.UserID(*userID),
+		Detector: sentiment.DetectorName(*detector),
+		DryRun:   *dryRun,
+	})
+	if err != nil {
+		log.Fatalf("Failed to re-score tweets: %s", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("Failed to print the report: %s", err)
+	}
+}
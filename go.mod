@@ -17,5 +17,6 @@ require (
 	github.com/go-playground/validator/v10 v10.4.1
 	github.com/google/uuid v1.1.2
 	github.com/guregu/dynamo v1.10.0
+	golang.org/x/text v0.3.3
 	google.golang.org/api v0.34.0
 )
@@ -0,0 +1,18 @@
+package ctxval
+
+import (
+	"context"
+)
+
+const adminKey = authContextKey("admin")
+
+// IsAdmin returns whether the authenticated user of the given context is an admin.
+func IsAdmin(ctx context.Context) bool {
+	admin, _ := ctx.Value(adminKey).(bool)
+	return admin
+}
+
+// SetAdmin returns new context which has whether the authenticated user is an admin for the context value.
+func SetAdmin(ctx context.Context, admin bool) context.Context {
+	return context.WithValue(ctx, adminKey, admin)
+}
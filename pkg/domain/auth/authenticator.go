@@ -11,5 +11,7 @@ type Authenticator interface {
 	Authenticate(ctx context.Context) (context.Context, error)
 	IsAuthenticated(ctx context.Context) bool
 	UserID(ctx context.Context) (model.UserID, error)
+	// IsAdmin reports whether the authenticated user is an admin.
+	IsAdmin(ctx context.Context) bool
 	ListUserID(ctx context.Context, pageToken string) (ids []model.UserID, nextPageToken string, err error)
 }
@@ -0,0 +1,14 @@
+package job
+
+import (
+	"context"
+
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+)
+
+// Dispatcher dispatches jobs which are processed asynchronously.
+type Dispatcher interface {
+	DispatchSearchDeletion(ctx context.Context, searchID model.SearchID, userID model.UserID) error
+	DispatchSearchBackfill(ctx context.Context, searchID model.SearchID, userID model.UserID) error
+	DispatchTweetRescoring(ctx context.Context, rescoring *model.TweetRescoring) error
+}
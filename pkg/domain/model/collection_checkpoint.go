@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// CollectionCheckpoint is the window of tweet IDs which is being filled by the collection.
+// The collection pages backwards from the newest tweet to the cursor of the search,
+// and the checkpoint is saved after each page, so that the next run resumes the unfilled range if the run fails.
+type CollectionCheckpoint struct {
+	// SinceTweetID is the lower bound (exclusive) of the window, which is the cursor when the window was opened.
+	SinceTweetID TweetID `json:",string"`
+	// MaxTweetID is the upper bound (inclusive) of the unfilled range. Newer tweets of the window were collected.
+	MaxTweetID TweetID `json:",string"`
+	// UntilTweetID is the newest tweet of the window, which becomes the cursor when the window is filled.
+	UntilTweetID TweetID `json:",string"`
+	UpdatedAt    time.Time
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// CollectionRun is the record of a collection of tweets of a search.
+type CollectionRun struct {
+	SearchID   SearchID
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// PagesFetched is the number of requests to the search API.
+	PagesFetched int
+	// TweetsSeen is the number of tweets returned by the search API.
+	TweetsSeen int
+	// TweetsSkipped is the number of tweets which were not stored because they were dropped by filter rules or already stored.
+	TweetsSkipped int
+	// FilteredTweets is the number of tweets dropped by each filter rule.
+	FilteredTweets map[FilterRuleName]int
+	TweetsStored   int64
+	// Notes describe why the run was skipped or stopped, or how it was resumed.
+	Notes []string
+	// Error is the error which failed the run. Empty means the run succeeded.
+	Error string
+}
+
+// NewCollectionRun creates CollectionRun which starts at the given time.
+func NewCollectionRun(searchID SearchID, startedAt time.Time) *CollectionRun {
+	return &CollectionRun{
+		SearchID:  searchID,
+		StartedAt: startedAt,
+		Notes:     []string{},
+	}
+}
+
+// AddNote appends a note to the run.
+func (r *CollectionRun) AddNote(note string) {
+	r.Notes = append(r.Notes, note)
+}
+
+// Finish records the end of the run with its error.
+func (r *CollectionRun) Finish(at time.Time, err error) {
+	r.FinishedAt = at
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
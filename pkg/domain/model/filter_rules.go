@@ -0,0 +1,176 @@
+package model
+
+import (
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hareku/emosearch-api/pkg/domain/twitter"
+)
+
+// FilterRuleName is the name of a rule which drops collected tweets before sentiment detection.
+type FilterRuleName string
+
+const (
+	// FilterRuleBlockedAuthor drops tweets posted by the blocked authors.
+	FilterRuleBlockedAuthor = FilterRuleName("BlockedAuthor")
+
+	// FilterRuleBlockedDomain drops tweets which link to the blocked domains or their subdomains.
+	FilterRuleBlockedDomain = FilterRuleName("BlockedDomain")
+
+	// FilterRuleForbiddenKeyword drops tweets which contain any of the forbidden keywords.
+	FilterRuleForbiddenKeyword = FilterRuleName("ForbiddenKeyword")
+
+	// FilterRuleRequiredKeyword drops tweets which do not contain all of the required keywords.
+	FilterRuleRequiredKeyword = FilterRuleName("RequiredKeyword")
+
+	// FilterRuleMediaOnly drops tweets which have media and no text except URLs, mentions and hashtags.
+	FilterRuleMediaOnly = FilterRuleName("MediaOnly")
+
+	// FilterRuleMinTextLength drops tweets whose text is shorter than the minimum length.
+	FilterRuleMinTextLength = FilterRuleName("MinTextLength")
+)
+
+// FilterRules is the configuration of rules which drop collected tweets.
+// Keywords, domains and authors are compared case-insensitively.
+type FilterRules struct {
+	BlockedDomains []string
+	// BlockedAuthors are screen names without "@".
+	BlockedAuthors    []string
+	RequiredKeywords  []string
+	ForbiddenKeywords []string
+	// MinTextLength is the minimum number of characters of the text except URLs, mentions and hashtags.
+	// Nil means the length is not limited, or it follows the global rules in the rules of a search.
+	MinTextLength    *int
+	ExcludeMediaOnly bool
+}
+
+// DefaultFilterRules returns the global rules which are applied until admins configure them.
+// Tweets which link to videos or anonymous questions, and short tweets are not suitable for sentiment detection.
+func DefaultFilterRules() *FilterRules {
+	minTextLength := 50
+	return &FilterRules{
+		BlockedDomains:    []string{"youtu.be", "youtube.com", "nicovideo.jp", "nico.ms", "peing.net"},
+		BlockedAuthors:    []string{},
+		RequiredKeywords:  []string{},
+		ForbiddenKeywords: []string{},
+		MinTextLength:     &minTextLength,
+		ExcludeMediaOnly:  true,
+	}
+}
+
+// Merge returns the rules which apply both of the rules.
+// MinTextLength of the given rules overrides the receiver's one if it is set.
+func (r *FilterRules) Merge(other *FilterRules) *FilterRules {
+	if other == nil {
+		return r
+	}
+
+	merged := &FilterRules{
+		BlockedDomains:    append(append([]string{}, r.BlockedDomains...), other.BlockedDomains...),
+		BlockedAuthors:    append(append([]string{}, r.BlockedAuthors...), other.BlockedAuthors...),
+		RequiredKeywords:  append(append([]string{}, r.RequiredKeywords...), other.RequiredKeywords...),
+		ForbiddenKeywords: append(append([]string{}, r.ForbiddenKeywords...), other.ForbiddenKeywords...),
+		MinTextLength:     r.MinTextLength,
+		ExcludeMediaOnly:  r.ExcludeMediaOnly || other.ExcludeMediaOnly,
+	}
+	if other.MinTextLength != nil {
+		merged.MinTextLength = other.MinTextLength
+	}
+	return merged
+}
+
+// Apply returns the name of the first rule which drops the tweet. Empty name means the tweet passes all rules.
+func (r *FilterRules) Apply(tweet *twitter.Tweet) FilterRuleName {
+	if tweet.User != nil && containsFold(r.BlockedAuthors, tweet.User.ScreenName) {
+		return FilterRuleBlockedAuthor
+	}
+
+	for _, u := range tweet.Entities.URLs {
+		if isBlockedURL(u.ExpandedURL, r.BlockedDomains) {
+			return FilterRuleBlockedDomain
+		}
+	}
+
+	text := strings.ToLower(tweet.Text)
+	for _, keyword := range r.ForbiddenKeywords {
+		if strings.Contains(text, strings.ToLower(keyword)) {
+			return FilterRuleForbiddenKeyword
+		}
+	}
+	for _, keyword := range r.RequiredKeywords {
+		if !strings.Contains(text, strings.ToLower(keyword)) {
+			return FilterRuleRequiredKeyword
+		}
+	}
+
+	body := tweetBodyText(tweet)
+	if r.ExcludeMediaOnly && len(tweet.Entities.Media) > 0 && body == "" {
+		return FilterRuleMediaOnly
+	}
+	if r.MinTextLength != nil && utf8.RuneCountInString(body) < *r.MinTextLength {
+		return FilterRuleMinTextLength
+	}
+
+	return ""
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func isBlockedURL(rawURL string, domains []string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, domain := range domains {
+		domain = strings.ToLower(domain)
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// tweetBodyText returns the text of the tweet except URLs, mentions, hashtags and media.
+// Indices of entities are counted in characters.
+func tweetBodyText(tweet *twitter.Tweet) string {
+	rs := []rune(tweet.Text)
+	removed := make([]bool, len(rs))
+	remove := func(start, end int) {
+		for i := start; i < end && i < len(rs); i++ {
+			if i >= 0 {
+				removed[i] = true
+			}
+		}
+	}
+
+	for _, e := range tweet.Entities.URLs {
+		remove(e.Start, e.End)
+	}
+	for _, e := range tweet.Entities.Mentions {
+		remove(e.Start, e.End)
+	}
+	for _, e := range tweet.Entities.HashTags {
+		remove(e.Start, e.End)
+	}
+	for _, e := range tweet.Entities.Media {
+		remove(e.Start, e.End)
+	}
+
+	var b strings.Builder
+	for i, r := range rs {
+		if !removed[i] {
+			b.WriteRune(r)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,183 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hareku/emosearch-api/pkg/domain/twitter"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestFilterRules_Apply(t *testing.T) {
+	rules := &FilterRules{
+		BlockedDomains:    []string{"YouTube.com"},
+		BlockedAuthors:    []string{"Spammer"},
+		RequiredKeywords:  []string{"Go"},
+		ForbiddenKeywords: []string{"ÄRGER"},
+		MinTextLength:     intPtr(5),
+		ExcludeMediaOnly:  true,
+	}
+
+	tests := []struct {
+		name  string
+		tweet twitter.Tweet
+		want  FilterRuleName
+	}{
+		{
+			name:  "pass",
+			tweet: twitter.Tweet{User: &twitter.User{ScreenName: "jack"}, Text: "I love go language"},
+			want:  "",
+		},
+		{
+			name:  "blocked author is compared case-insensitively",
+			tweet: twitter.Tweet{User: &twitter.User{ScreenName: "SPAMMER"}, Text: "I love go language"},
+			want:  FilterRuleBlockedAuthor,
+		},
+		{
+			name: "subdomain of blocked domain",
+			tweet: twitter.Tweet{
+				Text:     "I love go language https://t.co/a",
+				Entities: twitter.Entities{URLs: []twitter.URL{{Start: 19, End: 33, ExpandedURL: "https://m.youtube.com/watch"}}},
+			},
+			want: FilterRuleBlockedDomain,
+		},
+		{
+			name: "domain which only ends with blocked domain",
+			tweet: twitter.Tweet{
+				Text:     "I love go language https://t.co/a",
+				Entities: twitter.Entities{URLs: []twitter.URL{{Start: 19, End: 33, ExpandedURL: "https://notyoutube.com/"}}},
+			},
+			want: "",
+		},
+		{
+			name:  "forbidden keyword is folded with non-ASCII letters",
+			tweet: twitter.Tweet{Text: "go macht mir ärger"},
+			want:  FilterRuleForbiddenKeyword,
+		},
+		{
+			name:  "forbidden keyword precedes required keyword",
+			tweet: twitter.Tweet{Text: "nur Ärger hier"},
+			want:  FilterRuleForbiddenKeyword,
+		},
+		{
+			name:  "required keyword is missing",
+			tweet: twitter.Tweet{Text: "I love rust language"},
+			want:  FilterRuleRequiredKeyword,
+		},
+		{
+			name: "media only",
+			tweet: twitter.Tweet{
+				Text:     "#go https://t.co/m",
+				Entities: twitter.Entities{HashTags: []twitter.HashTag{{Start: 0, End: 3, Tag: "go"}}, Media: []twitter.Medium{{Start: 4, End: 18}}},
+			},
+			want: FilterRuleMediaOnly,
+		},
+		{
+			name: "text length does not count entities",
+			tweet: twitter.Tweet{
+				Text:     "@jack go!",
+				Entities: twitter.Entities{Mentions: []twitter.Mention{{Start: 0, End: 5, Tag: "jack"}}},
+			},
+			want: FilterRuleMinTextLength,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := rules.Apply(&tt.tweet); got != tt.want {
+			t.Errorf("%s: Apply() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFilterRules_Merge(t *testing.T) {
+	global := &FilterRules{
+		BlockedDomains:   []string{"youtube.com"},
+		BlockedAuthors:   []string{"spammer"},
+		MinTextLength:    intPtr(50),
+		ExcludeMediaOnly: true,
+	}
+
+	tests := []struct {
+		name   string
+		search *FilterRules
+		want   *FilterRules
+	}{
+		{
+			name:   "nil rules of the search",
+			search: nil,
+			want:   global,
+		},
+		{
+			name:   "lists are combined, and the global min text length is kept",
+			search: &FilterRules{BlockedDomains: []string{"peing.net"}, RequiredKeywords: []string{"go"}},
+			want: &FilterRules{
+				BlockedDomains:    []string{"youtube.com", "peing.net"},
+				BlockedAuthors:    []string{"spammer"},
+				RequiredKeywords:  []string{"go"},
+				ForbiddenKeywords: []string{},
+				MinTextLength:     intPtr(50),
+				ExcludeMediaOnly:  true,
+			},
+		},
+		{
+			name:   "min text length of the search overrides the global one",
+			search: &FilterRules{MinTextLength: intPtr(0)},
+			want: &FilterRules{
+				BlockedDomains:    []string{"youtube.com"},
+				BlockedAuthors:    []string{"spammer"},
+				RequiredKeywords:  []string{},
+				ForbiddenKeywords: []string{},
+				MinTextLength:     intPtr(0),
+				ExcludeMediaOnly:  true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := global.Merge(tt.search); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Merge() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	// Merging must not modify the global rules which are shared by searches.
+	global.Merge(&FilterRules{BlockedDomains: []string{"peing.net"}})
+	if len(global.BlockedDomains) != 1 {
+		t.Errorf("Merge() modified the receiver: %v", global.BlockedDomains)
+	}
+}
+
+func Test_tweetBodyText(t *testing.T) {
+	tests := []struct {
+		tweet twitter.Tweet
+		want  string
+	}{
+		{
+			tweet: twitter.Tweet{
+				Text: "楽しい #go @jack https://t.co/a",
+				Entities: twitter.Entities{
+					HashTags: []twitter.HashTag{{Start: 4, End: 7}},
+					Mentions: []twitter.Mention{{Start: 8, End: 13}},
+					URLs:     []twitter.URL{{Start: 14, End: 28}},
+				},
+			},
+			want: "楽しい",
+		},
+		{
+			// Indices out of the text are ignored.
+			tweet: twitter.Tweet{
+				Text:     "hello",
+				Entities: twitter.Entities{URLs: []twitter.URL{{Start: -1, End: 1}, {Start: 4, End: 100}}},
+			},
+			want: "ell",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tweetBodyText(&tt.tweet); got != tt.want {
+			t.Errorf("tweetBodyText(%q) = %q, want %q", tt.tweet.Text, got, tt.want)
+		}
+	}
+}
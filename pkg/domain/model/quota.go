@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+const (
+	// DefaultQuotaMaxActiveSearches is the maximum number of active searches of users who have no specific quota.
+	DefaultQuotaMaxActiveSearches = 10
+
+	// DefaultQuotaMaxTweetsPerMonth is the maximum number of tweets stored in a month for users who have no specific quota.
+	DefaultQuotaMaxTweetsPerMonth = 50000
+
+	// DefaultQuotaMinIntervalMinutes is the shortest collection interval for users who have no specific quota.
+	DefaultQuotaMinIntervalMinutes = MinSearchIntervalMinutes
+)
+
+// QuotaName is the name of a limit of Quota.
+type QuotaName string
+
+const (
+	// QuotaMaxActiveSearches limits the number of active searches.
+	QuotaMaxActiveSearches = QuotaName("MaxActiveSearches")
+
+	// QuotaMaxTweetsPerMonth limits the number of tweets stored in a calendar month (UTC).
+	QuotaMaxTweetsPerMonth = QuotaName("MaxTweetsPerMonth")
+
+	// QuotaMinIntervalMinutes limits the collection frequency of searches.
+	QuotaMinIntervalMinutes = QuotaName("MinIntervalMinutes")
+)
+
+// Quota is the plan limits of a user.
+type Quota struct {
+	MaxActiveSearches  int
+	MaxTweetsPerMonth  int64
+	MinIntervalMinutes int
+}
+
+// DefaultQuota returns the quota of users who have no specific quota.
+func DefaultQuota() Quota {
+	return Quota{
+		MaxActiveSearches:  DefaultQuotaMaxActiveSearches,
+		MaxTweetsPerMonth:  DefaultQuotaMaxTweetsPerMonth,
+		MinIntervalMinutes: DefaultQuotaMinIntervalMinutes,
+	}
+}
+
+// MinInterval returns the shortest collection interval.
+func (q Quota) MinInterval() time.Duration {
+	return time.Duration(q.MinIntervalMinutes) * time.Minute
+}
+
+// UserUsage is the usage of a user in a calendar month (UTC), which is limited by Quota.
+type UserUsage struct {
+	// Month is formatted as "2006-01".
+	Month            string
+	StoredTweetCount int64
+}
+
+// UserUsageMonth returns the month of UserUsage which the given time belongs to.
+func UserUsageMonth(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
@@ -1,18 +1,123 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
+)
 
 // SearchID is the identifier of Search domain.
 type SearchID string
 
+// SearchStatus represents whether tweets of a search are collected.
+type SearchStatus string
+
+const (
+	// SearchStatusActive means tweets of the search are collected.
+	SearchStatusActive = SearchStatus("ACTIVE")
+
+	// SearchStatusPaused means collection of the search is paused.
+	SearchStatusPaused = SearchStatus("PAUSED")
+
+	// SearchStatusArchived means the search is finished, and its tweets are no longer collected.
+	SearchStatusArchived = SearchStatus("ARCHIVED")
+
+	// SearchStatusDeleting means the search and its tweets are being deleted.
+	SearchStatusDeleting = SearchStatus("DELETING")
+)
+
+// SearchPauseReason represents why a search was paused by the system.
+type SearchPauseReason string
+
+const (
+	// SearchPauseReasonReauthorization means the search was paused because the Twitter token of the user was revoked.
+	// It is resumed when the user registers a new token.
+	SearchPauseReasonReauthorization = SearchPauseReason("REAUTHORIZATION_REQUIRED")
+)
+
+const (
+	// DefaultSearchIntervalMinutes is the collection interval of searches which do not configure it.
+	DefaultSearchIntervalMinutes = 30
+
+	// MinSearchIntervalMinutes is the minimum collection interval, because the collection batch runs every 5 minutes.
+	MinSearchIntervalMinutes = 5
+
+	// MaxSearchIntervalMinutes is the maximum collection interval.
+	MaxSearchIntervalMinutes = 1440
+
+	// AdaptiveIntervalFactor limits adaptive intervals to [Interval / AdaptiveIntervalFactor, Interval * AdaptiveIntervalFactor]
+	// of the interval configured by the user, within MinSearchIntervalMinutes and MaxSearchIntervalMinutes.
+	AdaptiveIntervalFactor = 4
+)
+
 // Search is the structure of a searching configuration.
 type Search struct {
 	SearchID            SearchID
 	UserID              UserID
 	Title               string
 	Query               string
+	Status              SearchStatus
 	LastSearchUpdatedAt *time.Time
 	NextSearchUpdateAt  time.Time
-	CreatedAt           time.Time
-	UpdatedAt           time.Time
+	// NextSearchUpdateReason describes why NextSearchUpdateAt was chosen.
+	NextSearchUpdateReason string
+	// PauseReason is set when the search was paused by the system. Empty means it was not paused by the system.
+	PauseReason SearchPauseReason
+	// IntervalMinutes is the collection interval configured by the user.
+	IntervalMinutes int
+	// AdaptiveInterval enables adjusting the interval by the number of tweets found in the last collection,
+	// within AdaptiveIntervalRange.
+	AdaptiveInterval bool
+	// EffectiveIntervalMinutes is the interval adjusted by adaptive scheduling.
+	EffectiveIntervalMinutes int
+	// SinceTweetID is the cursor of collection. Tweets newer than it are collected by the next run.
+	// Nil means the cursor is derived from the latest collected tweet.
+	SinceTweetID *TweetID `json:"-"`
+	// CollectionCheckpoint is the window being filled by the collection. Nil means no collection is in progress.
+	CollectionCheckpoint *CollectionCheckpoint
+	// DeletedTweetCount is the progress of deletion while the status is SearchStatusDeleting.
+	DeletedTweetCount int64
+	// SentimentDetector is the detector of collected tweets. Empty means the configured default detector.
+	SentimentDetector sentiment.DetectorName
+	// FilterRules drops collected tweets in addition to the global rules. Nil means only the global rules are applied.
+	FilterRules *FilterRules
+	// Backfill is the progress of collecting past tweets. Nil means the search has never been backfilled.
+	Backfill  *SearchBackfill
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// IsActive reports whether tweets of the search should be collected.
+// Searches created before SearchStatus was introduced have an empty status, and they are active.
+func (s *Search) IsActive() bool {
+	return s.Status == "" || s.Status == SearchStatusActive
+}
+
+// Interval returns the collection interval configured by the user.
+func (s *Search) Interval() time.Duration {
+	if s.IntervalMinutes == 0 {
+		return DefaultSearchIntervalMinutes * time.Minute
+	}
+	return time.Duration(s.IntervalMinutes) * time.Minute
+}
+
+// AdaptiveIntervalRange returns the minimum and maximum intervals which adaptive scheduling can adjust the interval to.
+func (s *Search) AdaptiveIntervalRange() (time.Duration, time.Duration) {
+	min := s.Interval() / AdaptiveIntervalFactor
+	if min < MinSearchIntervalMinutes*time.Minute {
+		min = MinSearchIntervalMinutes * time.Minute
+	}
+	max := s.Interval() * AdaptiveIntervalFactor
+	if max > MaxSearchIntervalMinutes*time.Minute {
+		max = MaxSearchIntervalMinutes * time.Minute
+	}
+	return min, max
+}
+
+// EffectiveInterval returns the interval which is used to schedule the next collection.
+func (s *Search) EffectiveInterval() time.Duration {
+	if s.AdaptiveInterval && s.EffectiveIntervalMinutes > 0 {
+		return time.Duration(s.EffectiveIntervalMinutes) * time.Minute
+	}
+	return s.Interval()
 }
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"github.com/hareku/emosearch-api/pkg/domain/twitter"
+)
+
+// SearchBackfillStatus represents the state of the backfill of a search.
+type SearchBackfillStatus string
+
+const (
+	// SearchBackfillRunning means past tweets of the search are being collected.
+	SearchBackfillRunning = SearchBackfillStatus("RUNNING")
+
+	// SearchBackfillCompleted means all past tweets which the search API provides were collected.
+	SearchBackfillCompleted = SearchBackfillStatus("COMPLETED")
+
+	// SearchBackfillAborted means the backfill was stopped before it was completed, see SearchBackfill.Reason.
+	SearchBackfillAborted = SearchBackfillStatus("ABORTED")
+)
+
+// SearchBackfill is the progress of collecting tweets which were posted before the search was created,
+// or its query was changed. It pages backwards across multiple jobs.
+type SearchBackfill struct {
+	Status SearchBackfillStatus
+	// MaxTweetID is the cursor of the backfill. Tweets older than it are collected by the next page.
+	MaxTweetID TweetID `json:",string"`
+	// FoundTweets is the number of tweets returned by the search API.
+	FoundTweets int64
+	// StoredTweets is the number of tweets stored with sentiment detection.
+	StoredTweets int64
+	// OldestTweetAt is the creation time of the oldest found tweet.
+	OldestTweetAt *time.Time
+	Reason        string
+	StartedAt     time.Time
+	UpdatedAt     time.Time
+	CompletedAt   *time.Time
+}
+
+// NewSearchBackfill creates SearchBackfill which collects tweets posted before the given time.
+func NewSearchBackfill(at time.Time) *SearchBackfill {
+	return &SearchBackfill{
+		Status:     SearchBackfillRunning,
+		MaxTweetID: TweetID(twitter.MinTweetIDAt(at)),
+		StartedAt:  at,
+		UpdatedAt:  at,
+	}
+}
+
+// IsRunning reports whether the backfill should be continued.
+func (b *SearchBackfill) IsRunning() bool {
+	return b != nil && b.Status == SearchBackfillRunning
+}
+
+// Finish marks the backfill as completed or aborted.
+func (b *SearchBackfill) Finish(status SearchBackfillStatus, reason string, at time.Time) {
+	b.Status = status
+	b.Reason = reason
+	b.UpdatedAt = at
+	b.CompletedAt = &at
+}
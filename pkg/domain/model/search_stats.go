@@ -0,0 +1,20 @@
+package model
+
+import "github.com/hareku/emosearch-api/pkg/domain/sentiment"
+
+// SearchStats is the counters of tweets collected by a search.
+type SearchStats struct {
+	TotalCount  int64
+	LabelCounts map[sentiment.Label]int64
+	Daily       []DailySearchStats
+}
+
+// DailySearchStatsDateLayout is the layout of DailySearchStats.Date.
+const DailySearchStatsDateLayout = "2006-01-02"
+
+// DailySearchStats is the counters of tweets collected by a search per day (UTC) of TweetCreatedAt.
+type DailySearchStats struct {
+	Date        string
+	TotalCount  int64
+	LabelCounts map[sentiment.Label]int64
+}
@@ -0,0 +1,174 @@
+package model
+
+import (
+	"time"
+
+	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
+)
+
+// TimelineBucket represents the time span of a bucket of sentiment timeline.
+type TimelineBucket string
+
+const (
+	// TimelineBucketHour aggregates tweets by hour.
+	TimelineBucketHour = TimelineBucket("hour")
+
+	// TimelineBucketDay aggregates tweets by day.
+	TimelineBucketDay = TimelineBucket("day")
+)
+
+// Truncate returns the start time of the bucket which the given time belongs to.
+// Buckets are aligned to the location of the given time.
+func (b TimelineBucket) Truncate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	if b == TimelineBucketDay {
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+
+	return time.Date(y, m, d, t.Hour(), 0, 0, 0, t.Location())
+}
+
+// Next returns the start time of the bucket following the bucket which starts at the given time.
+func (b TimelineBucket) Next(t time.Time) time.Time {
+	if b == TimelineBucketDay {
+		return t.AddDate(0, 0, 1)
+	}
+
+	return t.Add(time.Hour)
+}
+
+// Duration returns the nominal length of the bucket.
+func (b TimelineBucket) Duration() time.Duration {
+	if b == TimelineBucketDay {
+		return 24 * time.Hour
+	}
+
+	return time.Hour
+}
+
+// SentimentTimelineBucket is the aggregated sentiment of tweets in a bucket.
+type SentimentTimelineBucket struct {
+	StartAt    time.Time
+	TotalCount int64
+	Counts     map[sentiment.Label]int64
+	// EmotionCounts is the number of tweets by their dominant emotion. Tweets without emotions are not counted.
+	EmotionCounts map[sentiment.Emotion]int64
+	AverageScore  sentiment.Score
+}
+
+// SentimentTimeline aggregates tweets into buckets.
+type SentimentTimeline struct {
+	bucket  TimelineBucket
+	from    time.Time
+	to      time.Time
+	buckets []SentimentTimelineBucket
+	sums    []scoreSum
+	index   map[int64]int
+}
+
+type scoreSum struct {
+	count    int64
+	positive float64
+	negative float64
+	neutral  float64
+}
+
+// NewSentimentTimeline creates SentimentTimeline which has empty buckets in the range [from, to).
+func NewSentimentTimeline(bucket TimelineBucket, from time.Time, to time.Time) *SentimentTimeline {
+	tl := &SentimentTimeline{
+		bucket: bucket,
+		from:   from,
+		to:     to,
+		index:  map[int64]int{},
+	}
+
+	for t := bucket.Truncate(from); t.Before(to); t = bucket.Next(t) {
+		tl.index[t.Unix()] = len(tl.buckets)
+		tl.buckets = append(tl.buckets, SentimentTimelineBucket{
+			StartAt:       t,
+			Counts:        map[sentiment.Label]int64{},
+			EmotionCounts: map[sentiment.Emotion]int64{},
+		})
+		tl.sums = append(tl.sums, scoreSum{})
+	}
+
+	return tl
+}
+
+// Add adds the tweet into the bucket which the tweet belongs to.
+// Tweets out of the range of the timeline are ignored.
+func (tl *SentimentTimeline) Add(tweet *Tweet) {
+	createdAt := tweet.TweetCreatedAt.In(tl.from.Location())
+	if createdAt.Before(tl.from) || !createdAt.Before(tl.to) {
+		return
+	}
+
+	i, ok := tl.index[tl.bucket.Truncate(createdAt).Unix()]
+	if !ok {
+		return
+	}
+
+	b := &tl.buckets[i]
+	b.TotalCount++
+	b.Counts[tweet.SentimentLabel]++
+	if tweet.DominantEmotion != "" {
+		b.EmotionCounts[tweet.DominantEmotion]++
+	}
+
+	score := tweet.SentimentScore
+	if score != nil && score.Positive != nil && score.Negative != nil && score.Neutral != nil {
+		s := &tl.sums[i]
+		s.count++
+		s.positive += *score.Positive
+		s.negative += *score.Negative
+		s.neutral += *score.Neutral
+	}
+}
+
+// AddDailyStats adds the counters of a day (UTC) into the bucket of the day, so the timeline must have day buckets in UTC.
+// The counters have no scores and emotions, so they are not aggregated into the bucket.
+// Days out of the buckets are ignored.
+func (tl *SentimentTimeline) AddDailyStats(stats *DailySearchStats) {
+	day, err := time.Parse(DailySearchStatsDateLayout, stats.Date)
+	if err != nil {
+		return
+	}
+
+	i, ok := tl.index[day.Unix()]
+	if !ok {
+		return
+	}
+
+	b := &tl.buckets[i]
+	b.TotalCount += stats.TotalCount
+	for label, count := range stats.LabelCounts {
+		b.Counts[label] += count
+	}
+}
+
+// Buckets returns the aggregated buckets in chronological order.
+func (tl *SentimentTimeline) Buckets() []SentimentTimelineBucket {
+	res := make([]SentimentTimelineBucket, len(tl.buckets))
+
+	for i, b := range tl.buckets {
+		s := tl.sums[i]
+		if s.count > 0 {
+			positive := s.positive / float64(s.count)
+			negative := s.negative / float64(s.count)
+			neutral := s.neutral / float64(s.count)
+			b.AverageScore = sentiment.Score{
+				Positive: &positive,
+				Negative: &negative,
+				Neutral:  &neutral,
+			}
+		}
+		res[i] = b
+	}
+
+	return res
+}
+
+// Len returns the number of buckets.
+func (tl *SentimentTimeline) Len() int {
+	return len(tl.buckets)
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
+)
+
+func TestSentimentTimeline_Day(t *testing.T) {
+	jst := time.FixedZone("Asia/Tokyo", 9*60*60)
+	from := time.Date(2021, 1, 1, 10, 0, 0, 0, jst)
+	to := time.Date(2021, 1, 4, 0, 0, 0, 0, jst)
+	tl := NewSentimentTimeline(TimelineBucketDay, from, to)
+
+	// Tweets are stored in UTC, but bucketed by days of the location of from.
+	for _, createdAt := range []time.Time{
+		time.Date(2020, 12, 31, 15, 0, 0, 0, time.UTC), // before from
+		time.Date(2021, 1, 1, 14, 59, 59, 0, time.UTC), // 2021-01-01 23:59:59 JST
+		time.Date(2021, 1, 1, 15, 0, 0, 0, time.UTC),   // 2021-01-02 00:00:00 JST
+		time.Date(2021, 1, 3, 15, 0, 0, 0, time.UTC),   // equals to
+	} {
+		tl.Add(&Tweet{TweetCreatedAt: createdAt, SentimentLabel: sentiment.LabelPositive})
+	}
+
+	buckets := tl.Buckets()
+	wantStarts := []time.Time{
+		time.Date(2021, 1, 1, 0, 0, 0, 0, jst),
+		time.Date(2021, 1, 2, 0, 0, 0, 0, jst),
+		time.Date(2021, 1, 3, 0, 0, 0, 0, jst),
+	}
+	wantCounts := []int64{1, 1, 0}
+	if len(buckets) != len(wantStarts) {
+		t.Fatalf("len(Buckets()) = %d, want %d", len(buckets), len(wantStarts))
+	}
+	for i, b := range buckets {
+		if !b.StartAt.Equal(wantStarts[i]) {
+			t.Errorf("buckets[%d].StartAt = %v, want %v", i, b.StartAt, wantStarts[i])
+		}
+		if b.TotalCount != wantCounts[i] || b.Counts[sentiment.LabelPositive] != wantCounts[i] {
+			t.Errorf("buckets[%d] counts = %d, %v, want %d", i, b.TotalCount, b.Counts, wantCounts[i])
+		}
+	}
+
+	// Empty days are returned with empty counts and no average score.
+	empty := buckets[2]
+	if empty.Counts == nil || empty.EmotionCounts == nil {
+		t.Errorf("counts of empty bucket are nil: %+v", empty)
+	}
+	if empty.AverageScore.Positive != nil {
+		t.Errorf("AverageScore of empty bucket = %+v, want nil scores", empty.AverageScore)
+	}
+}
+
+func TestSentimentTimeline_Hour(t *testing.T) {
+	from := time.Date(2021, 1, 1, 0, 30, 0, 0, time.UTC)
+	to := time.Date(2021, 1, 1, 3, 0, 0, 0, time.UTC)
+	tl := NewSentimentTimeline(TimelineBucketHour, from, to)
+	if tl.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", tl.Len())
+	}
+
+	score := func(positive, negative, neutral float64) *sentiment.Score {
+		return &sentiment.Score{Positive: &positive, Negative: &negative, Neutral: &neutral}
+	}
+	createdAt := time.Date(2021, 1, 1, 1, 15, 0, 0, time.UTC)
+	tl.Add(&Tweet{TweetCreatedAt: createdAt, SentimentLabel: sentiment.LabelPositive, SentimentScore: score(0.8, 0.1, 0.1), DominantEmotion: sentiment.EmotionJoy})
+	tl.Add(&Tweet{TweetCreatedAt: createdAt, SentimentLabel: sentiment.LabelNeutral, SentimentScore: score(0.2, 0.1, 0.7)})
+	// Tweets without scores are counted, but not averaged.
+	tl.Add(&Tweet{TweetCreatedAt: createdAt, SentimentLabel: sentiment.LabelUnknown})
+
+	b := tl.Buckets()[1]
+	if b.TotalCount != 3 {
+		t.Errorf("TotalCount = %d, want 3", b.TotalCount)
+	}
+	if b.EmotionCounts[sentiment.EmotionJoy] != 1 || len(b.EmotionCounts) != 1 {
+		t.Errorf("EmotionCounts = %v, want only 1 joy", b.EmotionCounts)
+	}
+
+	got := b.AverageScore
+	if got.Positive == nil || got.Negative == nil || got.Neutral == nil {
+		t.Fatalf("AverageScore = %+v, want scores", got)
+	}
+	for _, s := range []struct {
+		got, want float64
+	}{{*got.Positive, 0.5}, {*got.Negative, 0.1}, {*got.Neutral, 0.4}} {
+		if math.Abs(s.got-s.want) > 1e-9 {
+			t.Errorf("AverageScore = %v, %v, %v, want 0.5, 0.1, 0.4", *got.Positive, *got.Negative, *got.Neutral)
+			break
+		}
+	}
+}
+
+func TestSentimentTimeline_AddDailyStats(t *testing.T) {
+	from := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	to := time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC)
+	tl := NewSentimentTimeline(TimelineBucketDay, from, to)
+
+	for _, stats := range []DailySearchStats{
+		{Date: "2020-12-31", TotalCount: 5, LabelCounts: map[sentiment.Label]int64{sentiment.LabelPositive: 5}},
+		{Date: "2021-01-01", TotalCount: 3, LabelCounts: map[sentiment.Label]int64{sentiment.LabelPositive: 2, sentiment.LabelNegative: 1}},
+		{Date: "2021-01-03", TotalCount: 5, LabelCounts: map[sentiment.Label]int64{sentiment.LabelPositive: 5}},
+	} {
+		tl.AddDailyStats(&stats)
+	}
+
+	buckets := tl.Buckets()
+	if len(buckets) != 2 {
+		t.Fatalf("len(Buckets()) = %d, want 2", len(buckets))
+	}
+	if b := buckets[0]; b.TotalCount != 3 || b.Counts[sentiment.LabelPositive] != 2 || b.Counts[sentiment.LabelNegative] != 1 {
+		t.Errorf("buckets[0] counts = %d, %v, want 3, 2 positive and 1 negative", b.TotalCount, b.Counts)
+	}
+	if b := buckets[1]; b.TotalCount != 0 || b.AverageScore.Positive != nil {
+		t.Errorf("buckets[1] = %+v, want an empty bucket", b)
+	}
+}
@@ -20,13 +20,23 @@ type TwitterUser struct {
 
 // Tweet is the structure of a tweet.
 type Tweet struct {
-	TweetID            TweetID `json:",string"`
-	SearchID           SearchID
-	AuthorID           int64 `json:",string"`
-	User               *TwitterUser
-	Text               string
-	SentimentScore     *sentiment.Score
-	SentimentLabel     sentiment.Label
+	TweetID  TweetID `json:",string"`
+	SearchID SearchID
+	AuthorID int64 `json:",string"`
+	User     *TwitterUser
+	Text     string
+	// NormalizedText is the text which was preprocessed for sentiment detection. Empty means it was detected by Text.
+	NormalizedText string
+	SentimentScore *sentiment.Score
+	SentimentLabel sentiment.Label
+	// SentimentDetector is the detector which detected the score. Nil means it was detected before detectors were recorded.
+	SentimentDetector *sentiment.DetectorInfo
+	// SentimentConfidence is the agreement of detectors of an ensemble. Nil means it was detected by a single detector.
+	SentimentConfidence *float64
+	// Emotions is the intensities of emotions. Nil means the detector does not detect emotions.
+	Emotions *sentiment.EmotionScore
+	// DominantEmotion is the most intense emotion of Emotions. Empty means the tweet has no emotions.
+	DominantEmotion    sentiment.Emotion
 	Entities           twitter.Entities
 	ExpirationUnixTime int64 `json:"-"`
 	TweetCreatedAt     time.Time
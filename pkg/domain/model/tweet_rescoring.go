@@ -0,0 +1,51 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
+)
+
+// TweetRescoring is the progress and the report of re-scoring stored tweets of a search with a sentiment detector.
+// It pages backwards across multiple jobs, and the report is carried over to the next job.
+type TweetRescoring struct {
+	SearchID SearchID `json:"search_id"`
+	UserID   UserID   `json:"user_id"`
+	// Detector is the detector which re-scores tweets. Empty means the detector of the search.
+	Detector sentiment.DetectorName `json:"detector,omitempty"`
+	// DryRun reports how labels would change without updating tweets.
+	DryRun bool `json:"dry_run"`
+	// UntilTweetID is the cursor of the re-scoring. Tweets older than it are re-scored by the next page.
+	UntilTweetID TweetID `json:"until_tweet_id,string"`
+	// ScannedTweets is the number of tweets which were detected again.
+	ScannedTweets int64 `json:"scanned_tweets"`
+	// UpdatedTweets is the number of tweets whose sentiments were updated.
+	UpdatedTweets int64 `json:"updated_tweets"`
+	// ChangedLabels is the number of tweets whose labels were changed.
+	ChangedLabels int64 `json:"changed_labels"`
+	// LabelChanges is the number of changed labels by each change, keyed by "<previous>-><new>".
+	LabelChanges map[string]int64 `json:"label_changes"`
+	// FailedTweets is the number of tweets whose detection failed, they keep their sentiments.
+	FailedTweets int64      `json:"failed_tweets"`
+	Completed    bool       `json:"completed"`
+	StartedAt    time.Time  `json:"started_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+}
+
+// AddLabelChange counts the change of the label of a tweet.
+func (r *TweetRescoring) AddLabelChange(from, to sentiment.Label) {
+	if r.LabelChanges == nil {
+		r.LabelChanges = map[string]int64{}
+	}
+	r.ChangedLabels++
+	r.LabelChanges[fmt.Sprintf("%s->%s", from, to)]++
+}
+
+// Complete marks the re-scoring as completed.
+func (r *TweetRescoring) Complete(at time.Time) {
+	r.Completed = true
+	r.UpdatedAt = at
+	r.CompletedAt = &at
+}
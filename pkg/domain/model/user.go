@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 // UserID is the identifier of User domain.
 type UserID string
 
@@ -8,4 +10,22 @@ type User struct {
 	UserID                   UserID
 	TwitterAccessToken       string
 	TwitterAccessTokenSecret string
+	// Quota is configured by an admin. Nil means the default quota.
+	Quota *Quota
+	// ReauthorizationRequiredAt is when the Twitter token of the user was found revoked.
+	// Nil means the token is valid, otherwise searches of the user are paused until a new token is registered.
+	ReauthorizationRequiredAt *time.Time
+}
+
+// NeedsReauthorization reports whether the user must register a new Twitter token.
+func (u *User) NeedsReauthorization() bool {
+	return u.ReauthorizationRequiredAt != nil
+}
+
+// CurrentQuota returns the quota which is applied to the user.
+func (u *User) CurrentQuota() Quota {
+	if u == nil || u.Quota == nil {
+		return DefaultQuota()
+	}
+	return *u.Quota
 }
@@ -0,0 +1,163 @@
+package preprocess
+
+import (
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/hareku/emosearch-api/pkg/domain/twitter"
+	"golang.org/x/text/unicode/norm"
+)
+
+// EntityAction is the way to handle entities of a kind in texts.
+type EntityAction string
+
+const (
+	// EntityKeep keeps entities as they are.
+	EntityKeep = EntityAction("keep")
+
+	// EntityStrip removes entities.
+	EntityStrip = EntityAction("strip")
+
+	// EntityReplace replaces entities with placeholders, and hashtags with their tags without "#".
+	EntityReplace = EntityAction("replace")
+)
+
+const (
+	// URLPlaceholder replaces URLs.
+	URLPlaceholder = "URL"
+
+	// MentionPlaceholder replaces mentions.
+	MentionPlaceholder = "@user"
+
+	// MediaPlaceholder replaces media.
+	MediaPlaceholder = "MEDIA"
+)
+
+// Options configures steps of Preprocessor.
+type Options struct {
+	URLs     EntityAction
+	Mentions EntityAction
+	Media    EntityAction
+	HashTags EntityAction
+	// NormalizeWidth normalizes texts by NFKC, which folds full-width alphanumerics into half-width ones,
+	// and half-width katakana into full-width ones.
+	NormalizeWidth bool
+	// NormalizeEmoji removes variation selectors and skin tone modifiers of emoji, so that variants of an emoji are the same.
+	NormalizeEmoji bool
+}
+
+// DefaultOptions returns the options which strip URLs, mentions and media, keep tags of hashtags, and normalize characters.
+func DefaultOptions() Options {
+	return Options{
+		URLs:           EntityStrip,
+		Mentions:       EntityStrip,
+		Media:          EntityStrip,
+		HashTags:       EntityReplace,
+		NormalizeWidth: true,
+		NormalizeEmoji: true,
+	}
+}
+
+// Preprocessor normalizes texts of tweets before sentiment detection.
+type Preprocessor interface {
+	// Preprocess returns the normalized text of the tweet text and its entities.
+	// It is empty when the tweet has nothing but stripped entities, e.g. only URLs and mentions.
+	Preprocess(text string, entities twitter.Entities) string
+}
+
+type preprocessor struct {
+	options Options
+	steps   []func(string) string
+}
+
+// NewPreprocessor creates Preprocessor which is configured by the options.
+// HTML entities of texts (e.g. "&amp;") are always unescaped, because indices of tweet entities refer to unescaped texts.
+func NewPreprocessor(options Options) Preprocessor {
+	p := &preprocessor{options: options}
+	if options.NormalizeWidth {
+		p.steps = append(p.steps, norm.NFKC.String)
+	}
+	if options.NormalizeEmoji {
+		p.steps = append(p.steps, normalizeEmoji)
+	}
+	p.steps = append(p.steps, collapseSpaces)
+	return p
+}
+
+func (p *preprocessor) Preprocess(text string, entities twitter.Entities) string {
+	text = p.applyEntities(html.UnescapeString(text), entities)
+	for _, step := range p.steps {
+		text = step(text)
+	}
+	return text
+}
+
+// span is a range of characters which is replaced.
+type span struct {
+	start       int
+	end         int
+	replacement string
+}
+
+// applyEntities strips or replaces entities of the text. Indices of entities are counted in characters.
+// Entities which overlap with preceding ones or are out of the text are ignored.
+func (p *preprocessor) applyEntities(text string, entities twitter.Entities) string {
+	spans := []span{}
+	add := func(action EntityAction, start int, end int, placeholder string) {
+		switch action {
+		case EntityStrip:
+			spans = append(spans, span{start, end, ""})
+		case EntityReplace:
+			spans = append(spans, span{start, end, placeholder})
+		}
+	}
+
+	for _, e := range entities.URLs {
+		add(p.options.URLs, e.Start, e.End, URLPlaceholder)
+	}
+	for _, e := range entities.Mentions {
+		add(p.options.Mentions, e.Start, e.End, MentionPlaceholder)
+	}
+	for _, e := range entities.Media {
+		add(p.options.Media, e.Start, e.End, MediaPlaceholder)
+	}
+	for _, e := range entities.HashTags {
+		add(p.options.HashTags, e.Start, e.End, e.Tag)
+	}
+	if len(spans) == 0 {
+		return text
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	rs := []rune(text)
+	var b strings.Builder
+	cursor := 0
+	for _, s := range spans {
+		if s.start < cursor || s.end < s.start || s.end > len(rs) {
+			continue
+		}
+		b.WriteString(string(rs[cursor:s.start]))
+		// Spaces keep words around entities apart.
+		b.WriteString(" " + s.replacement + " ")
+		cursor = s.end
+	}
+	b.WriteString(string(rs[cursor:]))
+
+	return b.String()
+}
+
+// normalizeEmoji removes variation selectors and skin tone modifiers.
+func normalizeEmoji(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\uFE0E' || r == '\uFE0F' || (r >= '\U0001F3FB' && r <= '\U0001F3FF') {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// collapseSpaces trims spaces, and collapses consecutive spaces and newlines into a space.
+func collapseSpaces(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
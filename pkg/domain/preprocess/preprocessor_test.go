@@ -0,0 +1,43 @@
+package preprocess
+
+import (
+	"testing"
+
+	"github.com/hareku/emosearch-api/pkg/domain/twitter"
+)
+
+func TestPreprocessor_Preprocess(t *testing.T) {
+	// Indices are counted in characters of the unescaped text.
+	text := "@hareku ライブ最高&amp;ﾀﾉｼｲ！ #ＬＩＶＥ https://t.co/abc\n👍🏻❤️"
+	entities := twitter.Entities{
+		Mentions: []twitter.Mention{{Start: 0, End: 7, Tag: "hareku"}},
+		HashTags: []twitter.HashTag{{Start: 20, End: 25, Tag: "ＬＩＶＥ"}},
+		URLs:     []twitter.URL{{Start: 26, End: 42, URL: "https://t.co/abc"}},
+	}
+
+	tests := []struct {
+		name    string
+		options Options
+		want    string
+	}{
+		{"default", DefaultOptions(), "ライブ最高&タノシイ! LIVE 👍❤"},
+		{"replace", Options{URLs: EntityReplace, Mentions: EntityReplace, HashTags: EntityStrip}, "@user ライブ最高&ﾀﾉｼｲ！ URL 👍🏻❤️"},
+		{"keep", Options{}, "@hareku ライブ最高&ﾀﾉｼｲ！ #ＬＩＶＥ https://t.co/abc 👍🏻❤️"},
+	}
+
+	for _, tt := range tests {
+		if got := NewPreprocessor(tt.options).Preprocess(text, entities); got != tt.want {
+			t.Errorf("%s: preprocessed as %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPreprocessor_InvalidEntities(t *testing.T) {
+	entities := twitter.Entities{
+		URLs:     []twitter.URL{{Start: 3, End: 100}},
+		Mentions: []twitter.Mention{{Start: 0, End: 2}, {Start: 1, End: 3}},
+	}
+	if got := NewPreprocessor(DefaultOptions()).Preprocess("@a 楽しい", entities); got != "楽しい" {
+		t.Errorf("preprocessed as %q", got)
+	}
+}
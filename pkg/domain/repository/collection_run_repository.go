@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+)
+
+// CollectionRunRepository provides methods for the history of collections of a search.
+type CollectionRunRepository interface {
+	Store(ctx context.Context, run *model.CollectionRun) error
+	// ListBySearchID returns the latest runs of the search in descending order of the start time.
+	ListBySearchID(ctx context.Context, searchID model.SearchID, limit int64) ([]*model.CollectionRun, error)
+}
@@ -5,4 +5,10 @@ import "errors"
 var (
 	// ErrNotFound is returned when a specified item was not found from repository.
 	ErrNotFound = errors.New("requested item was not found")
+
+	// ErrConflict is returned when an item was changed by others while updating it.
+	ErrConflict = errors.New("requested item was changed by others")
+
+	// ErrTooManyItems is returned when a read of repository exceeds the specified number of items.
+	ErrTooManyItems = errors.New("requested items are too many")
 )
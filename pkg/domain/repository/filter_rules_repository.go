@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+)
+
+// FilterRulesRepository provides methods for the global filter rules which are applied to all searches.
+type FilterRulesRepository interface {
+	// FindGlobal returns ErrNotFound if the global rules have never been configured.
+	FindGlobal(ctx context.Context) (*model.FilterRules, error)
+	UpdateGlobal(ctx context.Context, rules *model.FilterRules) error
+}
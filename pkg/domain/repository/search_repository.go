@@ -20,5 +20,15 @@ type SearchRepository interface {
 	Find(ctx context.Context, userID model.UserID, searchID model.SearchID) (*model.Search, error)
 	Create(ctx context.Context, search *model.Search) error
 	Update(ctx context.Context, search *model.Search) error
+	// UpdateSettings updates the fields of the search which are configured by its user, except for the query.
+	UpdateSettings(ctx context.Context, search *model.Search) error
+	// UpdateQuery updates the settings and the query of the search, and resets its collection progress,
+	// unless its query was changed from previousQuery by others.
+	UpdateQuery(ctx context.Context, search *model.Search, previousQuery string) error
+	UpdateCollectionCursor(ctx context.Context, search *model.Search) error
+	UpdateSchedule(ctx context.Context, search *model.Search) error
+	UpdateBackfill(ctx context.Context, search *model.Search) error
+	// UpdateStatus changes the status and the schedule of the search unless its status was changed by others.
+	UpdateStatus(ctx context.Context, search *model.Search, status model.SearchStatus, reason model.SearchPauseReason) error
 	Delete(ctx context.Context, search *model.Search) error
 }
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+)
+
+// SearchStatsRepository provides methods for counters of tweets collected by a search.
+// Counters are incremented by TweetRepository when tweets are stored.
+type SearchStatsRepository interface {
+	Find(ctx context.Context, searchID model.SearchID) (*model.SearchStats, error)
+}
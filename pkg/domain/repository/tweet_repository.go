@@ -2,17 +2,26 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/hareku/emosearch-api/pkg/domain/model"
 	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
 )
 
 // TweetRepository provides CRUD methods for Tweet domain.
+// Store and BatchStore skip tweets which are already stored, and increment SearchStats by stored tweets.
 type TweetRepository interface {
 	Store(ctx context.Context, tweet *model.Tweet) error
-	BatchStore(ctx context.Context, tweets []*model.Tweet) error
+	// BatchStore returns the number of tweets which were newly stored.
+	BatchStore(ctx context.Context, tweets []*model.Tweet) (int, error)
 	LatestTweetID(ctx context.Context, searchID model.SearchID) (model.TweetID, error)
 	List(ctx context.Context, input *TweetRepositoryListInput) ([]model.Tweet, error)
+	// UpdateSentiments updates sentiments and normalized texts of the stored tweets, and moves the label counters of SearchStats.
+	// Tweets whose labels are not PreviousLabel anymore, or which were deleted, are skipped.
+	// It returns the number of updated tweets.
+	UpdateSentiments(ctx context.Context, updates []*TweetSentimentUpdate) (int, error)
+	DeleteBySearchID(ctx context.Context, searchID model.SearchID, limit int64) (deletedTweets int, done bool, err error)
+	AggregateSentimentTimeline(ctx context.Context, input *TweetRepositoryAggregateSentimentTimelineInput) ([]model.SentimentTimelineBucket, error)
 }
 
 // TweetRepositoryListInput is used for List method of Tweet repository.
@@ -21,4 +30,23 @@ type TweetRepositoryListInput struct {
 	Limit          int64
 	UntilID        model.TweetID
 	SentimentLabel *sentiment.Label
+	// DominantEmotion filters tweets by their dominant emotion.
+	DominantEmotion *sentiment.Emotion
+}
+
+// TweetSentimentUpdate is the new sentiment of a tweet and its label before the update.
+type TweetSentimentUpdate struct {
+	Tweet         *model.Tweet
+	PreviousLabel sentiment.Label
+}
+
+// TweetRepositoryAggregateSentimentTimelineInput is used for AggregateSentimentTimeline method of Tweet repository.
+// Day buckets are aggregated from the daily counters of SearchStats, so they are UTC days and have no scores and emotions.
+// Hour buckets are aggregated from tweets, and ErrTooManyItems is returned when the range has more than MaxTweets tweets.
+type TweetRepositoryAggregateSentimentTimelineInput struct {
+	SearchID  model.SearchID
+	Bucket    model.TimelineBucket
+	From      time.Time
+	To        time.Time
+	MaxTweets int64
 }
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+)
+
+// TweetRescoringRepository provides methods for the reports of re-scorings of a search.
+type TweetRescoringRepository interface {
+	// Store stores the report, and overwrites the report of the same re-scoring which was stored by the previous job.
+	Store(ctx context.Context, rescoring *model.TweetRescoring) error
+	// ListBySearchID returns the latest reports of the search in descending order of the start time.
+	ListBySearchID(ctx context.Context, searchID model.SearchID, limit int64) ([]*model.TweetRescoring, error)
+}
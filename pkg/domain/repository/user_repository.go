@@ -10,4 +10,8 @@ import (
 type UserRepository interface {
 	Create(ctx context.Context, user *model.User) error
 	FindByID(ctx context.Context, userID model.UserID) (*model.User, error)
+	// UpdateQuota updates the quota of the user. Nil quota resets it to the default.
+	UpdateQuota(ctx context.Context, userID model.UserID, quota *model.Quota) error
+	// UpdateAuthorization updates the Twitter token of the user and whether it must be reauthorized.
+	UpdateAuthorization(ctx context.Context, user *model.User) error
 }
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+)
+
+// UserUsageRepository provides methods to count the usage of users.
+type UserUsageRepository interface {
+	// FindMonthly returns the usage in the month which the given time belongs to.
+	FindMonthly(ctx context.Context, userID model.UserID, at time.Time) (*model.UserUsage, error)
+	// AddStoredTweets increments the stored tweets in the month which the given time belongs to.
+	AddStoredTweets(ctx context.Context, userID model.UserID, at time.Time, count int64) error
+}
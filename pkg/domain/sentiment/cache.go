@@ -0,0 +1,92 @@
+package sentiment
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+)
+
+// Cache stores outputs of detectors keyed by hashes of texts.
+type Cache interface {
+	// GetMulti returns cached outputs of the keys. Keys which are not cached are not contained.
+	GetMulti(ctx context.Context, keys []string) (map[string]DetectOutput, error)
+	PutMulti(ctx context.Context, outputs map[string]DetectOutput) error
+}
+
+type cachedDetector struct {
+	detector  Detector
+	cache     Cache
+	namespace string
+}
+
+// NewCachedDetector creates Detector which serves cached outputs of the detector.
+// Texts are keyed as they are given, because detectors receive texts which were already normalized by the preprocessor,
+// so that copy-pasted tweets with different URLs or mentions are detected once.
+// The namespace separates keys of detectors which share the cache.
+// Failures of the cache are logged, and texts are detected by the detector.
+func NewCachedDetector(detector Detector, cache Cache, namespace string) Detector {
+	return &cachedDetector{detector, cache, namespace}
+}
+
+func (d *cachedDetector) cacheKey(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return d.namespace + "#" + hex.EncodeToString(sum[:])
+}
+
+func (d *cachedDetector) BatchDetect(ctx context.Context, textList []*string) ([]DetectOutput, error) {
+	keys := []string{}
+	for _, text := range textList {
+		keys = append(keys, d.cacheKey(*text))
+	}
+
+	cached, err := d.cache.GetMulti(ctx, keys)
+	if err != nil {
+		log.Printf("Failed to get cached sentiment outputs: %s\n", err)
+		cached = map[string]DetectOutput{}
+	}
+
+	// Texts which are not cached are detected once per key.
+	missKeys := []string{}
+	missTexts := []*string{}
+	missed := map[string]bool{}
+	for i, key := range keys {
+		if _, ok := cached[key]; ok || missed[key] {
+			continue
+		}
+		missed[key] = true
+		missKeys = append(missKeys, key)
+		missTexts = append(missTexts, textList[i])
+	}
+	log.Printf("Sentiment cache (%s): %d hits, %d misses.\n", d.namespace, len(keys)-len(missKeys), len(missKeys))
+
+	if len(missTexts) > 0 {
+		detected, err := d.detector.BatchDetect(ctx, missTexts)
+		if err != nil {
+			return nil, err
+		}
+		if len(detected) != len(missTexts) {
+			return nil, fmt.Errorf("detector returned %d outputs for %d texts", len(detected), len(missTexts))
+		}
+
+		// Failed outputs are not cached, so that they are detected again.
+		outputs := map[string]DetectOutput{}
+		for i, key := range missKeys {
+			cached[key] = detected[i]
+			if detected[i].Err == nil {
+				outputs[key] = detected[i]
+			}
+		}
+		err = d.cache.PutMulti(ctx, outputs)
+		if err != nil {
+			log.Printf("Failed to cache sentiment outputs: %s\n", err)
+		}
+	}
+
+	res := []DetectOutput{}
+	for _, key := range keys {
+		res = append(res, cached[key])
+	}
+	return res, nil
+}
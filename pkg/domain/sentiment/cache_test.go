@@ -0,0 +1,68 @@
+package sentiment
+
+import (
+	"context"
+	"testing"
+)
+
+type countingDetector struct {
+	detected []string
+}
+
+func (d *countingDetector) BatchDetect(ctx context.Context, textList []*string) ([]DetectOutput, error) {
+	outputs := []DetectOutput{}
+	for _, text := range textList {
+		d.detected = append(d.detected, *text)
+		outputs = append(outputs, DetectOutput{Label: Label(*text)})
+	}
+	return outputs, nil
+}
+
+type mapCache map[string]DetectOutput
+
+func (c mapCache) GetMulti(ctx context.Context, keys []string) (map[string]DetectOutput, error) {
+	res := map[string]DetectOutput{}
+	for _, key := range keys {
+		if output, ok := c[key]; ok {
+			res[key] = output
+		}
+	}
+	return res, nil
+}
+
+func (c mapCache) PutMulti(ctx context.Context, outputs map[string]DetectOutput) error {
+	for key, output := range outputs {
+		c[key] = output
+	}
+	return nil
+}
+
+func Test_cachedDetector_BatchDetect(t *testing.T) {
+	detector := &countingDetector{}
+	d := NewCachedDetector(detector, mapCache{}, "test")
+
+	// Texts are normalized by the preprocessor before detection, e.g. URLs of copy-pasted tweets are stripped.
+	texts := []string{"楽しい", "楽しい", "悲しい"}
+	outputs, err := d.BatchDetect(context.Background(), []*string{&texts[0], &texts[1], &texts[2]})
+	if err != nil {
+		t.Fatalf("BatchDetect returned error: %s", err)
+	}
+	if len(detector.detected) != 2 {
+		t.Errorf("detector detected %v, want 2 texts", detector.detected)
+	}
+	if outputs[0].Label != outputs[1].Label || outputs[2].Label != Label(texts[2]) {
+		t.Errorf("outputs are %v", outputs)
+	}
+
+	text := "悲しい"
+	outputs, err = d.BatchDetect(context.Background(), []*string{&text})
+	if err != nil {
+		t.Fatalf("BatchDetect returned error: %s", err)
+	}
+	if len(detector.detected) != 2 {
+		t.Errorf("cached text was detected again: %v", detector.detected)
+	}
+	if outputs[0].Label != Label(texts[2]) {
+		t.Errorf("output is %v, want the cached one", outputs[0])
+	}
+}
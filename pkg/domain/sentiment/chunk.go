@@ -0,0 +1,105 @@
+package sentiment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrRetryable is wrapped by errors of detectors which may succeed by retrying, like throttling.
+var ErrRetryable = errors.New("retryable detection error")
+
+// BatchLimits is the limits of requests of a detection provider.
+type BatchLimits struct {
+	// MaxItems is the maximum number of texts per request. Zero means it is not limited.
+	MaxItems int
+	// MaxTextBytes is the maximum size of a text in UTF-8. Zero means it is not limited.
+	MaxTextBytes int
+	// MaxRetries is the number of retries of a request which failed with ErrRetryable.
+	MaxRetries int
+	// RetryBaseDelay is the delay before the first retry, which doubles on each retry.
+	RetryBaseDelay time.Duration
+}
+
+type chunkedDetector struct {
+	detector Detector
+	limits   BatchLimits
+}
+
+// NewChunkedDetector creates Detector which splits texts into requests within the limits,
+// truncates oversized texts, and retries requests which failed with ErrRetryable.
+func NewChunkedDetector(detector Detector, limits BatchLimits) Detector {
+	return &chunkedDetector{detector, limits}
+}
+
+// TruncateText truncates the text to maxBytes at a boundary of UTF-8 characters.
+func TruncateText(text string, maxBytes int) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+
+	end := maxBytes
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return text[:end]
+}
+
+func (d *chunkedDetector) BatchDetect(ctx context.Context, textList []*string) ([]DetectOutput, error) {
+	res := []DetectOutput{}
+	size := d.limits.MaxItems
+	if size <= 0 {
+		size = len(textList)
+	}
+
+	for start := 0; start < len(textList); start += size {
+		end := start + size
+		if end > len(textList) {
+			end = len(textList)
+		}
+
+		chunk := []*string{}
+		for _, text := range textList[start:end] {
+			if d.limits.MaxTextBytes > 0 && len(*text) > d.limits.MaxTextBytes {
+				truncated := TruncateText(*text, d.limits.MaxTextBytes)
+				text = &truncated
+			}
+			chunk = append(chunk, text)
+		}
+
+		outputs, err := d.detectWithRetry(ctx, chunk)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, outputs...)
+	}
+
+	return res, nil
+}
+
+func (d *chunkedDetector) detectWithRetry(ctx context.Context, textList []*string) ([]DetectOutput, error) {
+	delay := d.limits.RetryBaseDelay
+	for retries := 0; ; retries++ {
+		outputs, err := d.detector.BatchDetect(ctx, textList)
+		if err == nil {
+			if len(outputs) != len(textList) {
+				return nil, fmt.Errorf("detector returned %d outputs for %d texts", len(outputs), len(textList))
+			}
+			return outputs, nil
+		}
+		if !errors.Is(err, ErrRetryable) || retries >= d.limits.MaxRetries {
+			return nil, err
+		}
+
+		log.Printf("Sentiment detection failed, retrying in %s: %s\n", delay, err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("retrying detection was canceled: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,71 @@
+package sentiment
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		text     string
+		maxBytes int
+		want     string
+	}{
+		{"abc", 5, "abc"},
+		{"abcdef", 3, "abc"},
+		{"あいう", 9, "あいう"},
+		{"あいう", 8, "あい"},
+		{"あいう", 4, "あ"},
+		{"あいう", 2, ""},
+	}
+
+	for _, tt := range tests {
+		if got := TruncateText(tt.text, tt.maxBytes); got != tt.want {
+			t.Errorf("TruncateText(%q, %d) = %q, want %q", tt.text, tt.maxBytes, got, tt.want)
+		}
+	}
+}
+
+type flakyDetector struct {
+	failures int
+	sizes    []int
+}
+
+func (d *flakyDetector) BatchDetect(ctx context.Context, textList []*string) ([]DetectOutput, error) {
+	if d.failures > 0 {
+		d.failures--
+		return nil, fmt.Errorf("throttled: %w", ErrRetryable)
+	}
+	d.sizes = append(d.sizes, len(textList))
+
+	outputs := []DetectOutput{}
+	for _, text := range textList {
+		outputs = append(outputs, DetectOutput{Label: Label(*text)})
+	}
+	return outputs, nil
+}
+
+func Test_chunkedDetector_BatchDetect(t *testing.T) {
+	detector := &flakyDetector{failures: 2}
+	d := NewChunkedDetector(detector, BatchLimits{MaxItems: 2, MaxTextBytes: 4, MaxRetries: 2, RetryBaseDelay: time.Millisecond})
+
+	texts := []string{"a", "b", "c", "あい"}
+	outputs, err := d.BatchDetect(context.Background(), []*string{&texts[0], &texts[1], &texts[2], &texts[3]})
+	if err != nil {
+		t.Fatalf("BatchDetect returned error: %s", err)
+	}
+	if fmt.Sprint(detector.sizes) != "[2 2]" {
+		t.Errorf("texts were split into %v, want [2 2]", detector.sizes)
+	}
+	if len(outputs) != 4 || outputs[2].Label != "c" || outputs[3].Label != "あ" {
+		t.Errorf("outputs are %v", outputs)
+	}
+
+	detector = &flakyDetector{failures: 3}
+	d = NewChunkedDetector(detector, BatchLimits{MaxItems: 2, MaxRetries: 2, RetryBaseDelay: time.Millisecond})
+	if _, err := d.BatchDetect(context.Background(), []*string{&texts[0]}); err == nil {
+		t.Errorf("BatchDetect returned no error after retries were exhausted")
+	}
+}
@@ -9,6 +9,8 @@ type Score struct {
 	Positive *float64
 	Negative *float64
 	Neutral  *float64
+	// Mixed is the score of both positive and negative. Nil means the detector does not detect it.
+	Mixed *float64
 }
 
 // Label represents a label type of sentiment scores.
@@ -24,17 +26,67 @@ const (
 	// LabelNeutral labels a sentiment score as neutral.
 	LabelNeutral = Label("NEUTRAL")
 
-	// LabelUnknown labels a sentiment score as unknown.
+	// LabelMixed labels a sentiment score as both positive and negative.
+	LabelMixed = Label("MIXED")
+
+	// LabelUnknown labels a sentiment score as unknown, or its detection failed or was not confident.
 	LabelUnknown = Label("UNKNOWN")
 )
 
+// DetectorName is the name of a sentiment detector implementation.
+type DetectorName string
+
+const (
+	// DetectorBayes is the detector by Naive Bayes API.
+	DetectorBayes = DetectorName("bayes")
+
+	// DetectorComprehend is the detector by AWS Comprehend.
+	DetectorComprehend = DetectorName("comprehend")
+
+	// DetectorLexicon is the in-process detector by the bundled Japanese polarity lexicon.
+	DetectorLexicon = DetectorName("lexicon")
+
+	// DetectorEnsemble is the detector which combines outputs of several detectors.
+	DetectorEnsemble = DetectorName("ensemble")
+)
+
+// DetectorInfo identifies the detector and its model which detected a score.
+type DetectorInfo struct {
+	Name         DetectorName
+	ModelVersion string
+}
+
 // DetectOutput is the type of Detector.Detect method.
 type DetectOutput struct {
 	Score Score
 	Label Label
+	// Confidence is the agreement of detectors on the label in [0, 1]. Nil means it was detected by a single detector.
+	Confidence *float64
+	// Emotions is the intensities of emotions. Nil means the detector does not detect emotions.
+	Emotions *EmotionScore
+	Detector DetectorInfo
+	// Err is the error of detection of the text, which failed while other texts of the batch were detected.
+	// Label of the failed text is LabelUnknown.
+	Err error `json:"-" dynamo:"-"`
+}
+
+// NewFailedOutput creates DetectOutput of a text whose detection failed.
+func NewFailedOutput(detector DetectorInfo, err error) DetectOutput {
+	return DetectOutput{
+		Label:    LabelUnknown,
+		Detector: detector,
+		Err:      err,
+	}
 }
 
 // Detector provides sentiment detections.
 type Detector interface {
 	BatchDetect(ctx context.Context, textList []*string) ([]DetectOutput, error)
 }
+
+// DetectorProvider provides detectors which are selected by configuration.
+type DetectorProvider interface {
+	// Detector returns the detector of the name, or the configured default one if the name is empty.
+	// The configured fallback detector is used when the detector fails.
+	Detector(name DetectorName) (Detector, error)
+}
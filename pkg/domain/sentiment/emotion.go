@@ -0,0 +1,97 @@
+package sentiment
+
+// Emotion represents a category of emotions, which is finer than Label.
+type Emotion string
+
+const (
+	// EmotionJoy is the emotion of joy.
+	EmotionJoy = Emotion("JOY")
+
+	// EmotionAnger is the emotion of anger.
+	EmotionAnger = Emotion("ANGER")
+
+	// EmotionSadness is the emotion of sadness.
+	EmotionSadness = Emotion("SADNESS")
+
+	// EmotionFear is the emotion of fear.
+	EmotionFear = Emotion("FEAR")
+
+	// EmotionSurprise is the emotion of surprise.
+	EmotionSurprise = Emotion("SURPRISE")
+
+	// EmotionDisgust is the emotion of disgust.
+	EmotionDisgust = Emotion("DISGUST")
+)
+
+// Emotions is the list of all emotions.
+var Emotions = []Emotion{EmotionJoy, EmotionAnger, EmotionSadness, EmotionFear, EmotionSurprise, EmotionDisgust}
+
+// IsValid reports whether the emotion is one of Emotions.
+func (e Emotion) IsValid() bool {
+	for _, emotion := range Emotions {
+		if e == emotion {
+			return true
+		}
+	}
+	return false
+}
+
+// EmotionScore is the intensities of emotions of text in [0, 1].
+type EmotionScore struct {
+	Joy      float64
+	Anger    float64
+	Sadness  float64
+	Fear     float64
+	Surprise float64
+	Disgust  float64
+}
+
+// Get returns the intensity of the emotion.
+func (s *EmotionScore) Get(emotion Emotion) float64 {
+	switch emotion {
+	case EmotionJoy:
+		return s.Joy
+	case EmotionAnger:
+		return s.Anger
+	case EmotionSadness:
+		return s.Sadness
+	case EmotionFear:
+		return s.Fear
+	case EmotionSurprise:
+		return s.Surprise
+	case EmotionDisgust:
+		return s.Disgust
+	}
+	return 0
+}
+
+// Add adds the intensity to the emotion.
+func (s *EmotionScore) Add(emotion Emotion, v float64) {
+	switch emotion {
+	case EmotionJoy:
+		s.Joy += v
+	case EmotionAnger:
+		s.Anger += v
+	case EmotionSadness:
+		s.Sadness += v
+	case EmotionFear:
+		s.Fear += v
+	case EmotionSurprise:
+		s.Surprise += v
+	case EmotionDisgust:
+		s.Disgust += v
+	}
+}
+
+// Dominant returns the most intense emotion, or the empty emotion if the score has no emotions.
+// Ties are broken by the order of Emotions.
+func (s *EmotionScore) Dominant() Emotion {
+	var dominant Emotion
+	var max float64
+	for _, emotion := range Emotions {
+		if v := s.Get(emotion); v > max {
+			dominant, max = emotion, v
+		}
+	}
+	return dominant
+}
@@ -0,0 +1,194 @@
+package sentiment
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// EnsembleStrategy is the way to combine outputs of detectors of an ensemble.
+type EnsembleStrategy string
+
+const (
+	// EnsembleWeightedAverage labels the weighted average of scores.
+	EnsembleWeightedAverage = EnsembleStrategy("weighted")
+
+	// EnsembleMajorityVote labels the label which has the most weighted votes.
+	// Ties are labeled by the weighted average of scores.
+	EnsembleMajorityVote = EnsembleStrategy("vote")
+)
+
+// EnsembleMember is a detector of an ensemble and its weight.
+type EnsembleMember struct {
+	Detector Detector
+	Weight   float64
+}
+
+type ensembleDetector struct {
+	strategy EnsembleStrategy
+	members  []EnsembleMember
+	policy   LabelPolicy
+}
+
+// NewEnsembleDetector creates Detector which combines outputs of the members, and labels the combined scores by the policy.
+// Members which fail are ignored unless all members fail, and so are failed outputs of texts.
+// Confidence of outputs is the ratio of the weights of members which agree with the combined label.
+// Emotions are the weighted average of the members which detect emotions.
+func NewEnsembleDetector(strategy EnsembleStrategy, members []EnsembleMember, policy LabelPolicy) Detector {
+	return &ensembleDetector{strategy, members, policy}
+}
+
+type ensembleResult struct {
+	weight  float64
+	outputs []DetectOutput
+	err     error
+}
+
+func (d *ensembleDetector) BatchDetect(ctx context.Context, textList []*string) ([]DetectOutput, error) {
+	results := make([]ensembleResult, len(d.members))
+
+	var wg sync.WaitGroup
+	for i, member := range d.members {
+		wg.Add(1)
+		go func(i int, member EnsembleMember) {
+			defer wg.Done()
+			outputs, err := member.Detector.BatchDetect(ctx, textList)
+			if err == nil && len(outputs) != len(textList) {
+				err = fmt.Errorf("detector returned %d outputs for %d texts", len(outputs), len(textList))
+			}
+			results[i] = ensembleResult{member.Weight, outputs, err}
+		}(i, member)
+	}
+	wg.Wait()
+
+	succeeded := []ensembleResult{}
+	errs := []string{}
+	for _, result := range results {
+		if result.err != nil {
+			log.Printf("Sentiment detector of the ensemble failed, it is ignored: %s\n", result.err)
+			errs = append(errs, result.err.Error())
+			continue
+		}
+		succeeded = append(succeeded, result)
+	}
+	if len(succeeded) == 0 {
+		return nil, fmt.Errorf("all detectors of the ensemble failed: %s", strings.Join(errs, ", "))
+	}
+
+	outputs := []DetectOutput{}
+	for i := range textList {
+		outputs = append(outputs, d.combine(succeeded, i))
+	}
+	return outputs, nil
+}
+
+// combine combines the i-th outputs of the results.
+func (d *ensembleDetector) combine(results []ensembleResult, i int) DetectOutput {
+	var totalWeight, positive, negative, neutral, mixed, mixedWeight, emotionWeight float64
+	var emotions *EmotionScore
+	votes := map[Label]float64{}
+	versions := []string{}
+	errs := []string{}
+
+	for _, result := range results {
+		output := result.outputs[i]
+		versions = append(versions, fmt.Sprintf("%s:%s", output.Detector.Name, output.Detector.ModelVersion))
+		if output.Err != nil {
+			errs = append(errs, output.Err.Error())
+			continue
+		}
+		totalWeight += result.weight
+		positive += result.weight * scoreValue(output.Score.Positive)
+		negative += result.weight * scoreValue(output.Score.Negative)
+		neutral += result.weight * scoreValue(output.Score.Neutral)
+		if output.Score.Mixed != nil {
+			mixed += result.weight * *output.Score.Mixed
+			mixedWeight += result.weight
+		}
+		votes[output.Label] += result.weight
+		if output.Emotions != nil {
+			if emotions == nil {
+				emotions = &EmotionScore{}
+			}
+			for _, emotion := range Emotions {
+				emotions.Add(emotion, result.weight*output.Emotions.Get(emotion))
+			}
+			emotionWeight += result.weight
+		}
+	}
+	info := DetectorInfo{
+		Name:         DetectorEnsemble,
+		ModelVersion: strings.Join(versions, "+"),
+	}
+	if len(errs) == len(results) {
+		return NewFailedOutput(info, fmt.Errorf("all detectors of the ensemble failed: %s", strings.Join(errs, ", ")))
+	}
+	if totalWeight > 0 {
+		positive /= totalWeight
+		negative /= totalWeight
+		neutral /= totalWeight
+	}
+	if emotions != nil && emotionWeight > 0 {
+		averaged := &EmotionScore{}
+		for _, emotion := range Emotions {
+			averaged.Add(emotion, emotions.Get(emotion)/emotionWeight)
+		}
+		emotions = averaged
+	}
+
+	score := Score{
+		Positive: &positive,
+		Negative: &negative,
+		Neutral:  &neutral,
+	}
+	if mixedWeight > 0 {
+		mixed /= mixedWeight
+		score.Mixed = &mixed
+	}
+
+	label := d.policy.Label(score)
+	if d.strategy == EnsembleMajorityVote {
+		label = majorityLabel(votes, label)
+	}
+
+	confidence := 0.0
+	if totalWeight > 0 {
+		confidence = votes[label] / totalWeight
+	}
+
+	return DetectOutput{
+		Score:      score,
+		Label:      label,
+		Confidence: &confidence,
+		Emotions:   emotions,
+		Detector:   info,
+	}
+}
+
+func scoreValue(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// majorityLabel returns the label which has the most votes, or tieBreaker if the most votes are tied.
+func majorityLabel(votes map[Label]float64, tieBreaker Label) Label {
+	var best Label
+	var bestVotes float64
+	tied := false
+	for label, v := range votes {
+		switch {
+		case v > bestVotes:
+			best, bestVotes, tied = label, v, false
+		case v == bestVotes:
+			tied = true
+		}
+	}
+	if tied || best == "" {
+		return tieBreaker
+	}
+	return best
+}
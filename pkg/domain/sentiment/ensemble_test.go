@@ -0,0 +1,106 @@
+package sentiment
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+type fixedDetector struct {
+	name     DetectorName
+	positive float64
+	negative float64
+	neutral  float64
+	label    Label
+	err      error
+}
+
+func (d *fixedDetector) BatchDetect(ctx context.Context, textList []*string) ([]DetectOutput, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	outputs := []DetectOutput{}
+	for range textList {
+		outputs = append(outputs, DetectOutput{
+			Score:    Score{Positive: &d.positive, Negative: &d.negative, Neutral: &d.neutral},
+			Label:    d.label,
+			Detector: DetectorInfo{Name: d.name, ModelVersion: "v1"},
+		})
+	}
+	return outputs, nil
+}
+
+var (
+	positiveDetector = &fixedDetector{name: "a", positive: 0.8, negative: 0.1, neutral: 0.1, label: LabelPositive}
+	negativeDetector = &fixedDetector{name: "b", positive: 0.1, negative: 0.6, neutral: 0.3, label: LabelNegative}
+	failingDetector  = &fixedDetector{name: "c", err: errors.New("unavailable")}
+)
+
+func detectOne(t *testing.T, d Detector) DetectOutput {
+	t.Helper()
+	text := "text"
+	outputs, err := d.BatchDetect(context.Background(), []*string{&text})
+	if err != nil {
+		t.Fatalf("BatchDetect returned error: %s", err)
+	}
+	if len(outputs) != 1 {
+		t.Fatalf("BatchDetect returned %d outputs", len(outputs))
+	}
+	return outputs[0]
+}
+
+func Test_ensembleDetector_WeightedAverage(t *testing.T) {
+	d := NewEnsembleDetector(EnsembleWeightedAverage, []EnsembleMember{
+		{positiveDetector, 1},
+		{negativeDetector, 3},
+	}, DefaultLabelPolicy)
+	output := detectOne(t, d)
+
+	if got := *output.Score.Positive; math.Abs(got-0.275) > 1e-9 {
+		t.Errorf("positive score is %f, want 0.275", got)
+	}
+	if output.Label != LabelNegative {
+		t.Errorf("label is %s, want %s", output.Label, LabelNegative)
+	}
+	if got := *output.Confidence; math.Abs(got-0.75) > 1e-9 {
+		t.Errorf("confidence is %f, want 0.75", got)
+	}
+	if output.Detector.Name != DetectorEnsemble || output.Detector.ModelVersion != "a:v1+b:v1" {
+		t.Errorf("detector is %+v", output.Detector)
+	}
+}
+
+func Test_ensembleDetector_MajorityVote(t *testing.T) {
+	d := NewEnsembleDetector(EnsembleMajorityVote, []EnsembleMember{
+		{positiveDetector, 1},
+		{positiveDetector, 1},
+		{negativeDetector, 1},
+	}, DefaultLabelPolicy)
+	output := detectOne(t, d)
+
+	if output.Label != LabelPositive {
+		t.Errorf("label is %s, want %s", output.Label, LabelPositive)
+	}
+	if got := *output.Confidence; math.Abs(got-2.0/3) > 1e-9 {
+		t.Errorf("confidence is %f, want 2/3", got)
+	}
+}
+
+func Test_ensembleDetector_IgnoresFailedMembers(t *testing.T) {
+	d := NewEnsembleDetector(EnsembleWeightedAverage, []EnsembleMember{
+		{positiveDetector, 1},
+		{failingDetector, 1},
+	}, DefaultLabelPolicy)
+	output := detectOne(t, d)
+
+	if output.Label != LabelPositive || *output.Confidence != 1 {
+		t.Errorf("output is %s with confidence %f", output.Label, *output.Confidence)
+	}
+
+	d = NewEnsembleDetector(EnsembleWeightedAverage, []EnsembleMember{{failingDetector, 1}}, DefaultLabelPolicy)
+	text := "text"
+	if _, err := d.BatchDetect(context.Background(), []*string{&text}); err == nil {
+		t.Errorf("BatchDetect returned no error when all members failed")
+	}
+}
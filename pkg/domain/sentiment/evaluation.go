@@ -0,0 +1,179 @@
+package sentiment
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// LabeledText is a text of an evaluation dataset and its gold label.
+type LabeledText struct {
+	Text  string
+	Label Label
+}
+
+// LabelMetrics is the metrics of a label of an evaluation.
+type LabelMetrics struct {
+	Precision float64
+	Recall    float64
+	F1        float64
+	// Support is the number of texts whose gold label is the label.
+	Support int
+}
+
+// LatencyMetrics is the latency of requests of an evaluation.
+type LatencyMetrics struct {
+	Total time.Duration
+	// PerText is the average latency per text.
+	PerText time.Duration
+	// P50 and P95 are the percentiles of latencies of batches.
+	P50 time.Duration
+	P95 time.Duration
+}
+
+// Evaluation is the report of a detector on a labeled dataset.
+type Evaluation struct {
+	Total   int
+	Correct int
+	// Failed is the number of texts whose detection failed, they are predicted as LabelUnknown.
+	Failed   int
+	Accuracy float64
+	// MacroF1 is the average F1 of gold labels.
+	MacroF1 float64
+	Labels  map[Label]LabelMetrics
+	// Confusion is the number of texts by the gold label and the predicted label.
+	Confusion map[Label]map[Label]int
+	Latency   LatencyMetrics
+}
+
+// Evaluate detects the texts of the dataset by batches of batchSize, and compares labels with gold labels.
+// It returns an error if the detector fails as a whole, and failed outputs of texts are counted as wrong.
+func Evaluate(ctx context.Context, detector Detector, dataset []LabeledText, batchSize int) (*Evaluation, error) {
+	if batchSize <= 0 {
+		batchSize = len(dataset)
+	}
+
+	e := &Evaluation{
+		Total:     len(dataset),
+		Labels:    map[Label]LabelMetrics{},
+		Confusion: map[Label]map[Label]int{},
+	}
+	latencies := []time.Duration{}
+
+	for start := 0; start < len(dataset); start += batchSize {
+		end := start + batchSize
+		if end > len(dataset) {
+			end = len(dataset)
+		}
+
+		textList := []*string{}
+		for i := start; i < end; i++ {
+			textList = append(textList, &dataset[i].Text)
+		}
+
+		startedAt := time.Now()
+		outputs, err := detector.BatchDetect(ctx, textList)
+		latency := time.Since(startedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect texts %d-%d: %w", start, end-1, err)
+		}
+		if len(outputs) != len(textList) {
+			return nil, fmt.Errorf("detector returned %d outputs for %d texts", len(outputs), len(textList))
+		}
+		latencies = append(latencies, latency)
+		e.Latency.Total += latency
+
+		for i, output := range outputs {
+			predicted := output.Label
+			if output.Err != nil {
+				e.Failed++
+				predicted = LabelUnknown
+			}
+			e.add(dataset[start+i].Label, predicted)
+		}
+	}
+
+	e.summarize(latencies)
+	return e, nil
+}
+
+func (e *Evaluation) add(gold, predicted Label) {
+	if e.Confusion[gold] == nil {
+		e.Confusion[gold] = map[Label]int{}
+	}
+	e.Confusion[gold][predicted]++
+	if gold == predicted {
+		e.Correct++
+	}
+}
+
+func (e *Evaluation) summarize(latencies []time.Duration) {
+	if e.Total > 0 {
+		e.Accuracy = float64(e.Correct) / float64(e.Total)
+		e.Latency.PerText = e.Latency.Total / time.Duration(e.Total)
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	e.Latency.P50 = percentile(latencies, 0.5)
+	e.Latency.P95 = percentile(latencies, 0.95)
+
+	// Metrics are computed for gold labels, and for predicted labels which are not gold labels of any texts.
+	labels := map[Label]bool{}
+	for gold, predictions := range e.Confusion {
+		labels[gold] = true
+		for predicted := range predictions {
+			labels[predicted] = true
+		}
+	}
+
+	var f1Sum float64
+	var goldLabels int
+	for label := range labels {
+		var truePositive, predictedCount, support int
+		for gold, predictions := range e.Confusion {
+			predictedCount += predictions[label]
+			if gold == label {
+				truePositive = predictions[label]
+				for _, n := range predictions {
+					support += n
+				}
+			}
+		}
+
+		m := LabelMetrics{Support: support}
+		if predictedCount > 0 {
+			m.Precision = float64(truePositive) / float64(predictedCount)
+		}
+		if support > 0 {
+			m.Recall = float64(truePositive) / float64(support)
+		}
+		if m.Precision+m.Recall > 0 {
+			m.F1 = 2 * m.Precision * m.Recall / (m.Precision + m.Recall)
+		}
+		e.Labels[label] = m
+		if support > 0 {
+			f1Sum += m.F1
+			goldLabels++
+		}
+	}
+	if goldLabels > 0 {
+		e.MacroF1 = f1Sum / float64(goldLabels)
+	}
+}
+
+// percentile returns the percentile of the sorted durations by the nearest-rank method.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
@@ -0,0 +1,60 @@
+package sentiment
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+// textLabelDetector labels texts as their own texts, and fails texts which are "fail".
+type textLabelDetector struct{}
+
+func (d *textLabelDetector) BatchDetect(ctx context.Context, textList []*string) ([]DetectOutput, error) {
+	outputs := []DetectOutput{}
+	for _, text := range textList {
+		if *text == "fail" {
+			outputs = append(outputs, NewFailedOutput(DetectorInfo{}, errors.New("failed")))
+			continue
+		}
+		outputs = append(outputs, DetectOutput{Label: Label(*text)})
+	}
+	return outputs, nil
+}
+
+func TestEvaluate(t *testing.T) {
+	dataset := []LabeledText{
+		{string(LabelPositive), LabelPositive},
+		{string(LabelPositive), LabelPositive},
+		{string(LabelNegative), LabelPositive},
+		{string(LabelNegative), LabelNegative},
+		{"fail", LabelNegative},
+	}
+
+	e, err := Evaluate(context.Background(), &textLabelDetector{}, dataset, 2)
+	if err != nil {
+		t.Fatalf("Evaluate returned error: %s", err)
+	}
+
+	if e.Total != 5 || e.Correct != 3 || e.Failed != 1 {
+		t.Errorf("total: %d, correct: %d, failed: %d", e.Total, e.Correct, e.Failed)
+	}
+	if e.Accuracy != 0.6 {
+		t.Errorf("accuracy is %f, want 0.6", e.Accuracy)
+	}
+	if e.Confusion[LabelPositive][LabelNegative] != 1 || e.Confusion[LabelNegative][LabelUnknown] != 1 {
+		t.Errorf("unexpected confusion matrix: %v", e.Confusion)
+	}
+
+	positive := e.Labels[LabelPositive]
+	if positive.Precision != 1 || math.Abs(positive.Recall-2.0/3) > 1e-9 || positive.Support != 3 {
+		t.Errorf("unexpected positive metrics: %+v", positive)
+	}
+	negative := e.Labels[LabelNegative]
+	if negative.Precision != 0.5 || negative.Recall != 0.5 || negative.F1 != 0.5 {
+		t.Errorf("unexpected negative metrics: %+v", negative)
+	}
+	if want := (positive.F1 + negative.F1) / 2; math.Abs(e.MacroF1-want) > 1e-9 {
+		t.Errorf("macro F1 is %f, want %f", e.MacroF1, want)
+	}
+}
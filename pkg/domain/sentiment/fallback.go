@@ -0,0 +1,31 @@
+package sentiment
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+type fallbackDetector struct {
+	primary  Detector
+	fallback Detector
+}
+
+// NewFallbackDetector creates Detector which uses the fallback detector when the primary one fails.
+func NewFallbackDetector(primary Detector, fallback Detector) Detector {
+	return &fallbackDetector{primary, fallback}
+}
+
+func (d *fallbackDetector) BatchDetect(ctx context.Context, textList []*string) ([]DetectOutput, error) {
+	outputs, err := d.primary.BatchDetect(ctx, textList)
+	if err == nil {
+		return outputs, nil
+	}
+	log.Printf("Primary sentiment detector failed, fallback detector is used: %s\n", err)
+
+	outputs, fallbackErr := d.fallback.BatchDetect(ctx, textList)
+	if fallbackErr != nil {
+		return nil, fmt.Errorf("fallback detector error: %v, primary detector error: %w", fallbackErr, err)
+	}
+	return outputs, nil
+}
@@ -0,0 +1,97 @@
+package sentiment
+
+import (
+	"context"
+	"fmt"
+)
+
+// LabelPolicy decides labels of scores, so that labels mean the same thing regardless of detectors.
+// The zero value labels the highest score, and ties are labeled as LabelNeutral.
+type LabelPolicy struct {
+	// MinConfidence is the minimum top score to be labeled. Scores whose top score is lower are labeled as LabelUnknown.
+	MinConfidence float64
+	// MinMargin is the minimum difference between the top score and the second one.
+	// Scores whose margin is smaller are labeled as LabelUnknown.
+	MinMargin float64
+	// MixedMinScore is the minimum positive and negative scores to be labeled as LabelMixed.
+	// Zero means scores are labeled as LabelMixed only when the mixed score is the highest.
+	MixedMinScore float64
+}
+
+// DefaultLabelPolicy is the policy which labels the highest score without thresholds.
+var DefaultLabelPolicy = LabelPolicy{}
+
+// String returns the thresholds of the policy, which identify the policy of cached outputs.
+func (p LabelPolicy) String() string {
+	return fmt.Sprintf("confidence=%g,margin=%g,mixed=%g", p.MinConfidence, p.MinMargin, p.MixedMinScore)
+}
+
+type labelScore struct {
+	label Label
+	score float64
+}
+
+// Label decides the label of the score.
+// Scores which lack any of positive, negative and neutral scores are labeled as LabelUnknown.
+func (p LabelPolicy) Label(score Score) Label {
+	if score.Positive == nil || score.Negative == nil || score.Neutral == nil {
+		return LabelUnknown
+	}
+
+	positive, negative := *score.Positive, *score.Negative
+	if p.MixedMinScore > 0 && positive >= p.MixedMinScore && negative >= p.MixedMinScore {
+		return LabelMixed
+	}
+
+	candidates := []labelScore{
+		{LabelPositive, positive},
+		{LabelNegative, negative},
+		{LabelNeutral, *score.Neutral},
+	}
+	if score.Mixed != nil {
+		candidates = append(candidates, labelScore{LabelMixed, *score.Mixed})
+	}
+
+	top, second := candidates[0], labelScore{score: -1}
+	for _, c := range candidates[1:] {
+		switch {
+		case c.score > top.score:
+			top, second = c, top
+		case c.score > second.score:
+			second = c
+		}
+	}
+
+	if top.score < p.MinConfidence || top.score-second.score < p.MinMargin {
+		return LabelUnknown
+	}
+	if top.score == second.score {
+		return LabelNeutral
+	}
+	return top.label
+}
+
+type policyDetector struct {
+	detector Detector
+	policy   LabelPolicy
+}
+
+// NewPolicyDetector creates Detector which labels outputs of the detector by the policy.
+// Failed outputs keep LabelUnknown.
+func NewPolicyDetector(detector Detector, policy LabelPolicy) Detector {
+	return &policyDetector{detector, policy}
+}
+
+func (d *policyDetector) BatchDetect(ctx context.Context, textList []*string) ([]DetectOutput, error) {
+	outputs, err := d.detector.BatchDetect(ctx, textList)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range outputs {
+		if outputs[i].Err == nil {
+			outputs[i].Label = d.policy.Label(outputs[i].Score)
+		}
+	}
+	return outputs, nil
+}
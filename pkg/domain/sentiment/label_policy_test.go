@@ -0,0 +1,46 @@
+package sentiment
+
+import "testing"
+
+func newScore(positive, negative, neutral float64, mixed *float64) Score {
+	return Score{Positive: &positive, Negative: &negative, Neutral: &neutral, Mixed: mixed}
+}
+
+func TestLabelPolicy_Label(t *testing.T) {
+	mixed := 0.5
+	strict := LabelPolicy{MinConfidence: 0.5, MinMargin: 0.1, MixedMinScore: 0.4}
+
+	tests := []struct {
+		name   string
+		policy LabelPolicy
+		score  Score
+		want   Label
+	}{
+		{"highest score", DefaultLabelPolicy, newScore(0.34, 0.33, 0.33, nil), LabelPositive},
+		{"tie", DefaultLabelPolicy, newScore(0.4, 0.4, 0.2, nil), LabelNeutral},
+		{"highest mixed score", DefaultLabelPolicy, newScore(0.2, 0.2, 0.1, &mixed), LabelMixed},
+		{"missing score", DefaultLabelPolicy, Score{}, LabelUnknown},
+		{"low confidence", strict, newScore(0.45, 0.3, 0.25, nil), LabelUnknown},
+		{"small margin", strict, newScore(0.55, 0.0, 0.5, nil), LabelUnknown},
+		{"confident", strict, newScore(0.7, 0.1, 0.2, nil), LabelPositive},
+		{"positive and negative", strict, newScore(0.45, 0.45, 0.1, nil), LabelMixed},
+	}
+
+	for _, tt := range tests {
+		if got := tt.policy.Label(tt.score); got != tt.want {
+			t.Errorf("%s: labeled as %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPolicyDetector(t *testing.T) {
+	d := NewPolicyDetector(negativeDetector, LabelPolicy{MinConfidence: 0.7})
+	if output := detectOne(t, d); output.Label != LabelUnknown {
+		t.Errorf("labeled as %s, want %s", output.Label, LabelUnknown)
+	}
+
+	d = NewPolicyDetector(negativeDetector, DefaultLabelPolicy)
+	if output := detectOne(t, d); output.Label != LabelNegative {
+		t.Errorf("labeled as %s, want %s", output.Label, LabelNegative)
+	}
+}
@@ -2,12 +2,19 @@ package twitter
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrInvalidToken is returned by Client when the access token of the user is invalid, e.g. the user revoked the app.
+var ErrInvalidToken = errors.New("twitter access token is invalid or revoked")
+
+// SearchWindow is the period of past tweets which the search APIs provide.
+const SearchWindow = 7 * 24 * time.Hour
+
 // Client provides twitter actions.
 type Client interface {
-	Search(ctx context.Context, input *SearchInput) ([]Tweet, error)
+	Search(ctx context.Context, input *SearchInput) (*SearchOutput, error)
 }
 
 // SearchInput is the input for Search method.
@@ -19,6 +26,13 @@ type SearchInput struct {
 	MaxID                    int64
 }
 
+// SearchOutput is the output of Search method.
+type SearchOutput struct {
+	Tweets []Tweet
+	// RateLimit is the rate limit after the search, which is nil when the response has no rate limit headers.
+	RateLimit *RateLimit
+}
+
 // User represents Twitter user object.
 type User struct {
 	ID              int64 `json:",string"`
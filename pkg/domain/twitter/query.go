@@ -0,0 +1,389 @@
+package twitter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryError describes why a search query is invalid.
+type QueryError struct {
+	Message string
+}
+
+func (e *QueryError) Error() string {
+	return e.Message
+}
+
+func newQueryError(format string, a ...interface{}) *QueryError {
+	return &QueryError{Message: fmt.Sprintf(format, a...)}
+}
+
+type queryTokenKind int
+
+const (
+	queryTokenKeyword queryTokenKind = iota
+	queryTokenPhrase
+	queryTokenOperator
+	queryTokenOr
+	queryTokenOpen
+	queryTokenClose
+)
+
+type queryToken struct {
+	kind     queryTokenKind
+	negated  bool
+	operator string
+	value    string
+}
+
+func (t queryToken) isTerm() bool {
+	return t.kind == queryTokenKeyword || t.kind == queryTokenPhrase || t.kind == queryTokenOperator
+}
+
+func (t queryToken) isExcludeRetweets() bool {
+	return t.kind == queryTokenOperator && t.negated && t.operator == "filter" && t.value == "retweets"
+}
+
+func (t queryToken) String() string {
+	prefix := ""
+	if t.negated {
+		prefix = "-"
+	}
+
+	switch t.kind {
+	case queryTokenPhrase:
+		return prefix + `"` + t.value + `"`
+	case queryTokenOperator:
+		return prefix + t.operator + ":" + t.value
+	case queryTokenOr:
+		return "OR"
+	case queryTokenOpen:
+		return "("
+	case queryTokenClose:
+		return ")"
+	}
+	return prefix + t.value
+}
+
+// Query is a search query of Twitter standard search, which is parsed by ParseQuery.
+type Query struct {
+	tokens []queryToken
+}
+
+// excludeRetweetsToken is appended to queries of collection, because retweets are not collected.
+var excludeRetweetsToken = queryToken{kind: queryTokenOperator, negated: true, operator: "filter", value: "retweets"}
+
+// String returns the normalized query.
+// It does not contain "-filter:retweets", because it is always applied by ExcludingRetweets.
+func (q *Query) String() string {
+	return joinQueryTokens(q.tokens)
+}
+
+// ExcludingRetweets returns the normalized query which excludes retweets.
+func (q *Query) ExcludingRetweets() string {
+	return joinQueryTokens(append(q.tokens[:len(q.tokens):len(q.tokens)], excludeRetweetsToken))
+}
+
+func joinQueryTokens(tokens []queryToken) string {
+	var b strings.Builder
+	for i, t := range tokens {
+		if i > 0 && tokens[i-1].kind != queryTokenOpen && t.kind != queryTokenClose {
+			b.WriteByte(' ')
+		}
+		b.WriteString(t.String())
+	}
+	return b.String()
+}
+
+var (
+	screenNameRegexp = regexp.MustCompile(`^@?[A-Za-z0-9_]{1,15}$`)
+	langRegexp       = regexp.MustCompile(`^[A-Za-z]{2,3}$`)
+	operatorRegexp   = regexp.MustCompile(`^[A-Za-z_]+$`)
+)
+
+// queryFilters are the values of "filter:" operator.
+var queryFilters = map[string]bool{
+	"links":          true,
+	"media":          true,
+	"images":         true,
+	"videos":         true,
+	"native_video":   true,
+	"verified":       true,
+	"safe":           true,
+	"news":           true,
+	"hashtags":       true,
+	"mentions":       true,
+	"replies":        true,
+	"quote":          true,
+	"retweets":       true,
+	"nativeretweets": true,
+}
+
+// queryOperators normalizes and validates values of operators.
+var queryOperators = map[string]func(value string) (string, bool){
+	"from":         normalizeScreenName,
+	"to":           normalizeScreenName,
+	"lang":         normalizeLang,
+	"filter":       normalizeFilter,
+	"since":        normalizeDate,
+	"until":        normalizeDate,
+	"since_id":     normalizeNumber,
+	"max_id":       normalizeNumber,
+	"min_retweets": normalizeNumber,
+	"min_faves":    normalizeNumber,
+	"min_replies":  normalizeNumber,
+	"url":          func(value string) (string, bool) { return value, true },
+}
+
+func normalizeScreenName(value string) (string, bool) {
+	return strings.TrimPrefix(value, "@"), screenNameRegexp.MatchString(value)
+}
+
+func normalizeLang(value string) (string, bool) {
+	return strings.ToLower(value), langRegexp.MatchString(value)
+}
+
+func normalizeFilter(value string) (string, bool) {
+	value = strings.ToLower(value)
+	return value, queryFilters[value]
+}
+
+func normalizeDate(value string) (string, bool) {
+	_, err := time.Parse("2006-01-02", value)
+	return value, err == nil
+}
+
+func normalizeNumber(value string) (string, bool) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	return strconv.FormatInt(n, 10), err == nil && n >= 0
+}
+
+// ParseQuery parses and validates a search query of Twitter standard search.
+// Supported syntax is keywords, "quoted phrases", OR, parentheses, "-" for negation, #hashtags, @mentions
+// and the operators from:, to:, lang:, filter:, since:, until:, since_id:, max_id:, min_retweets:, min_faves:, min_replies: and url:.
+func ParseQuery(s string) (*Query, error) {
+	tokens, err := tokenizeQuery(s)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateQueryTokens(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Query{}
+	for _, t := range tokens {
+		if !t.isExcludeRetweets() {
+			q.tokens = append(q.tokens, t)
+		}
+	}
+
+	return q, nil
+}
+
+func tokenizeQuery(s string) ([]queryToken, error) {
+	var tokens []queryToken
+	rs := []rune(s)
+
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case isQuerySpace(r):
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: queryTokenOpen})
+			i++
+			continue
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: queryTokenClose})
+			i++
+			continue
+		}
+
+		negated := false
+		if r == '-' {
+			negated = true
+			i++
+			if i == len(rs) || isQuerySpace(rs[i]) || rs[i] == ')' {
+				return nil, newQueryError(`"-" must be followed by a search term`)
+			}
+			if rs[i] == '(' {
+				return nil, newQueryError(`"-" cannot be applied to a group`)
+			}
+		}
+
+		if rs[i] == '"' {
+			end := i + 1
+			for end < len(rs) && rs[end] != '"' {
+				end++
+			}
+			if end == len(rs) {
+				return nil, newQueryError("quoted phrase is not terminated")
+			}
+			phrase := strings.TrimSpace(string(rs[i+1 : end]))
+			if phrase == "" {
+				return nil, newQueryError("quoted phrase is empty")
+			}
+			tokens = append(tokens, queryToken{kind: queryTokenPhrase, negated: negated, value: phrase})
+			i = end + 1
+			continue
+		}
+
+		end := i
+		for end < len(rs) && !isQuerySpace(rs[end]) && rs[end] != '(' && rs[end] != ')' && rs[end] != '"' {
+			end++
+		}
+		word := string(rs[i:end])
+		i = end
+
+		t, err := newWordToken(word, negated)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+
+	return tokens, nil
+}
+
+func isQuerySpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '　'
+}
+
+func newWordToken(word string, negated bool) (queryToken, error) {
+	if word == "OR" {
+		if negated {
+			return queryToken{}, newQueryError(`"-" cannot be applied to "OR"`)
+		}
+		return queryToken{kind: queryTokenOr}, nil
+	}
+
+	sep := strings.Index(word, ":")
+	if sep <= 0 || !operatorRegexp.MatchString(word[:sep]) {
+		return queryToken{kind: queryTokenKeyword, negated: negated, value: word}, nil
+	}
+
+	name := strings.ToLower(word[:sep])
+	value := word[sep+1:]
+	if strings.HasPrefix(value, "//") {
+		// URLs like "https://example.com" are keywords.
+		return queryToken{kind: queryTokenKeyword, negated: negated, value: word}, nil
+	}
+
+	normalize, ok := queryOperators[name]
+	if !ok {
+		return queryToken{}, newQueryError(`operator "%s:" is not supported`, name)
+	}
+	if value == "" {
+		return queryToken{}, newQueryError(`operator "%s:" requires a value`, name)
+	}
+	normalized, ok := normalize(value)
+	if !ok {
+		return queryToken{}, newQueryError(`value %q of operator "%s:" is invalid`, value, name)
+	}
+
+	return queryToken{kind: queryTokenOperator, negated: negated, operator: name, value: normalized}, nil
+}
+
+func validateQueryTokens(tokens []queryToken) error {
+	depth := 0
+	positive := false
+
+	for i, t := range tokens {
+		var prev, next *queryToken
+		if i > 0 {
+			prev = &tokens[i-1]
+		}
+		if i < len(tokens)-1 {
+			next = &tokens[i+1]
+		}
+
+		switch t.kind {
+		case queryTokenOr:
+			if prev == nil || next == nil || !(prev.isTerm() || prev.kind == queryTokenClose) || !(next.isTerm() || next.kind == queryTokenOpen) {
+				return newQueryError(`"OR" must be placed between search terms`)
+			}
+		case queryTokenOpen:
+			depth++
+			if next != nil && next.kind == queryTokenClose {
+				return newQueryError("parentheses are empty")
+			}
+		case queryTokenClose:
+			depth--
+			if depth < 0 {
+				return newQueryError("parentheses are not balanced")
+			}
+		case queryTokenOperator:
+			if t.operator == "filter" && !t.negated && (t.value == "retweets" || t.value == "nativeretweets") {
+				return newQueryError(`"filter:%s" cannot be used, because retweets are always excluded`, t.value)
+			}
+			if t.isExcludeRetweets() && (depth > 0 || (prev != nil && prev.kind == queryTokenOr) || (next != nil && next.kind == queryTokenOr)) {
+				return newQueryError(`"-filter:retweets" cannot be combined with "OR" or parentheses`)
+			}
+		}
+
+		if t.isTerm() && !t.negated {
+			positive = true
+		}
+	}
+
+	if depth != 0 {
+		return newQueryError("parentheses are not balanced")
+	}
+	if !positive {
+		return newQueryError("query must contain at least one search term which is not excluded")
+	}
+
+	return nil
+}
+
+// v2Filters maps values of "filter:" operator into operators of Twitter API v2 search.
+var v2Filters = map[string]queryToken{
+	"links":          {kind: queryTokenOperator, operator: "has", value: "links"},
+	"media":          {kind: queryTokenOperator, operator: "has", value: "media"},
+	"images":         {kind: queryTokenOperator, operator: "has", value: "images"},
+	"videos":         {kind: queryTokenOperator, operator: "has", value: "videos"},
+	"native_video":   {kind: queryTokenOperator, operator: "has", value: "videos"},
+	"hashtags":       {kind: queryTokenOperator, operator: "has", value: "hashtags"},
+	"mentions":       {kind: queryTokenOperator, operator: "has", value: "mentions"},
+	"verified":       {kind: queryTokenOperator, operator: "is", value: "verified"},
+	"replies":        {kind: queryTokenOperator, operator: "is", value: "reply"},
+	"quote":          {kind: queryTokenOperator, operator: "is", value: "quote"},
+	"nativeretweets": {kind: queryTokenOperator, operator: "is", value: "retweet"},
+}
+
+// v2Operators are the operators which Twitter API v2 search supports as they are.
+var v2Operators = map[string]bool{
+	"from": true,
+	"to":   true,
+	"lang": true,
+	"url":  true,
+}
+
+// excludeRetweetsV2Token is the token of Twitter API v2 search which excludes retweets.
+var excludeRetweetsV2Token = queryToken{kind: queryTokenOperator, negated: true, operator: "is", value: "retweet"}
+
+// V2ExcludingRetweets returns the query of Twitter API v2 search which excludes retweets.
+// Operators are translated into the syntax of API v2, and QueryError is returned
+// if the query has operators which API v2 does not support (e.g. "since:" and "min_faves:").
+func (q *Query) V2ExcludingRetweets() (string, error) {
+	tokens := []queryToken{}
+	for _, t := range q.tokens {
+		if t.kind == queryTokenOperator && !v2Operators[t.operator] {
+			translated, ok := v2Filters[t.value]
+			if t.operator != "filter" || !ok {
+				return "", newQueryError(`operator "%s" is not supported by Twitter API v2`, t.String())
+			}
+			translated.negated = t.negated
+			t = translated
+		}
+		tokens = append(tokens, t)
+	}
+
+	return joinQueryTokens(append(tokens, excludeRetweetsV2Token)), nil
+}
@@ -0,0 +1,146 @@
+package twitter
+
+import (
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"cat", "cat"},
+		{"  cat   dog ", "cat dog"},
+		{"cat OR dog", "cat OR dog"},
+		{"cat or dog", "cat or dog"},
+		{`"happy hour"`, `"happy hour"`},
+		{`-"happy hour" beer`, `-"happy hour" beer`},
+		{"cat -dog", "cat -dog"},
+		{"(cat OR dog) lang:JA", "(cat OR dog) lang:ja"},
+		{"FROM:@jack", "from:jack"},
+		{"cat filter:Links -filter:replies", "cat filter:links -filter:replies"},
+		{"cat -filter:retweets", "cat"},
+		{"cat since:2020-01-02 until:2020-02-01", "cat since:2020-01-02 until:2020-02-01"},
+		{"cat min_faves:010", "cat min_faves:10"},
+		{"https://example.com", "https://example.com"},
+		{"#golang @jack", "#golang @jack"},
+		{"12:30", "12:30"},
+		{"猫　犬", "猫 犬"},
+	}
+
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q) returned error: %s", tt.query, err)
+			continue
+		}
+		if got := q.String(); got != tt.want {
+			t.Errorf("ParseQuery(%q).String() = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseQuery_Invalid(t *testing.T) {
+	queries := []string{
+		"",
+		"   ",
+		"OR cat",
+		"cat OR",
+		"cat OR OR dog",
+		"(cat OR) dog",
+		"cat -",
+		"-OR cat",
+		"-(cat OR dog)",
+		`"cat`,
+		`"" cat`,
+		"(cat",
+		"cat)",
+		"() cat",
+		"-cat",
+		"-from:jack",
+		"cat foo:bar",
+		"cat from:",
+		"cat from:this_name_is_too_long",
+		"cat lang:japanese",
+		"cat filter:unknown",
+		"cat filter:retweets",
+		"cat since:2020/01/02",
+		"cat since_id:abc",
+		"cat OR -filter:retweets",
+		"(cat -filter:retweets)",
+	}
+
+	for _, query := range queries {
+		q, err := ParseQuery(query)
+		if err == nil {
+			t.Errorf("ParseQuery(%q) = %q, want error", query, q.String())
+			continue
+		}
+		if _, ok := err.(*QueryError); !ok {
+			t.Errorf("ParseQuery(%q) returned %T, want *QueryError", query, err)
+		}
+	}
+}
+
+func TestQuery_ExcludingRetweets(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"cat", "cat -filter:retweets"},
+		{"cat -filter:retweets", "cat -filter:retweets"},
+		{"-filter:retweets (cat OR dog)", "(cat OR dog) -filter:retweets"},
+	}
+
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q) returned error: %s", tt.query, err)
+			continue
+		}
+		if got := q.ExcludingRetweets(); got != tt.want {
+			t.Errorf("ParseQuery(%q).ExcludingRetweets() = %q, want %q", tt.query, got, tt.want)
+		}
+		if got := q.String(); got+" -filter:retweets" != tt.want {
+			t.Errorf("ExcludingRetweets modified the query: %q", got)
+		}
+	}
+}
+
+func TestQuery_V2ExcludingRetweets(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"cat", "cat -is:retweet"},
+		{"(cat OR dog) from:jack lang:ja", "(cat OR dog) from:jack lang:ja -is:retweet"},
+		{"cat filter:links -filter:replies -filter:retweets", "cat has:links -is:reply -is:retweet"},
+	}
+
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q) returned error: %s", tt.query, err)
+			continue
+		}
+		got, err := q.V2ExcludingRetweets()
+		if err != nil {
+			t.Errorf("ParseQuery(%q).V2ExcludingRetweets() returned error: %s", tt.query, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseQuery(%q).V2ExcludingRetweets() = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	for _, query := range []string{"cat since:2020-01-02", "cat min_faves:10", "cat filter:safe"} {
+		q, err := ParseQuery(query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q) returned error: %s", query, err)
+			continue
+		}
+		if got, err := q.V2ExcludingRetweets(); err == nil {
+			t.Errorf("ParseQuery(%q).V2ExcludingRetweets() = %q, want error", query, got)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package twitter
+
+import (
+	"fmt"
+	"time"
+)
+
+// RateLimit is the rate limit window of the user token, which is returned with successful responses.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// Exhausted reports whether the next request with the token fails until Reset.
+// Unknown rate limits (nil) are not exhausted.
+func (r *RateLimit) Exhausted() bool {
+	return r != nil && r.Remaining <= 0
+}
+
+// Err returns RateLimitError which the next request with the token fails with.
+func (r *RateLimit) Err() *RateLimitError {
+	return &RateLimitError{Limit: r.Limit, Remaining: r.Remaining, Reset: r.Reset}
+}
+
+// RateLimitError is returned by Client when the rate limit window of the user token is exhausted.
+// Requests with the token fail until Reset.
+type RateLimitError struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded (%d/%d remaining), reset at %s", e.Remaining, e.Limit, e.Reset.Format(time.RFC3339))
+}
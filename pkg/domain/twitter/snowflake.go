@@ -0,0 +1,23 @@
+package twitter
+
+import "time"
+
+// twepoch is the epoch of Twitter Snowflake IDs in Unix milliseconds.
+const twepoch = 1288834974657
+
+// MinTweetIDAt returns the smallest Tweet ID which can be issued at the given time.
+// Tweet IDs are Snowflake IDs, so they are ordered by their creation time.
+func MinTweetIDAt(t time.Time) int64 {
+	ms := t.UnixNano()/int64(time.Millisecond) - twepoch
+	if ms < 0 {
+		return 0
+	}
+
+	return ms << 22
+}
+
+// TweetIDTime returns the time when the given Tweet ID was issued.
+func TweetIDTime(id int64) time.Time {
+	ms := (id >> 22) + twepoch
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
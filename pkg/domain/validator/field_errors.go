@@ -0,0 +1,29 @@
+package validator
+
+import (
+	"sort"
+	"strings"
+)
+
+// FieldErrors is ErrValidation which consists of messages keyed by field names.
+// It is used for validations which can not be described by struct tags.
+type FieldErrors map[string]string
+
+func (e FieldErrors) Error() string {
+	msgs := []string{}
+	for field, msg := range e {
+		msgs = append(msgs, field+": "+msg)
+	}
+	sort.Strings(msgs)
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns nil, because FieldErrors does not wrap any error.
+func (e FieldErrors) Unwrap() error {
+	return nil
+}
+
+// ToMap returns messages keyed by field names.
+func (e FieldErrors) ToMap() map[string]string {
+	return e
+}
@@ -0,0 +1,68 @@
+package awslambda
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hareku/emosearch-api/pkg/domain/job"
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+)
+
+type lambdaDispatcher struct {
+	client    *lambda.Lambda
+	functions LambdaDispatcherFunctions
+}
+
+// LambdaDispatcherFunctions is the names of AWS Lambda functions which process jobs.
+type LambdaDispatcherFunctions struct {
+	DeleteSearch   string
+	BackfillTweets string
+	RescoreTweets  string
+}
+
+// NewLambdaDispatcher creates Dispatcher which invokes AWS Lambda functions asynchronously.
+func NewLambdaDispatcher(client *lambda.Lambda, functions LambdaDispatcherFunctions) job.Dispatcher {
+	return &lambdaDispatcher{client, functions}
+}
+
+type searchEvent struct {
+	SearchID model.SearchID `json:"search_id"`
+	UserID   model.UserID   `json:"user_id"`
+}
+
+func (d *lambdaDispatcher) DispatchSearchDeletion(ctx context.Context, searchID model.SearchID, userID model.UserID) error {
+	return d.invoke(ctx, d.functions.DeleteSearch, searchEvent{searchID, userID})
+}
+
+func (d *lambdaDispatcher) DispatchSearchBackfill(ctx context.Context, searchID model.SearchID, userID model.UserID) error {
+	return d.invoke(ctx, d.functions.BackfillTweets, searchEvent{searchID, userID})
+}
+
+func (d *lambdaDispatcher) DispatchTweetRescoring(ctx context.Context, rescoring *model.TweetRescoring) error {
+	return d.invoke(ctx, d.functions.RescoreTweets, rescoring)
+}
+
+func (d *lambdaDispatcher) invoke(ctx context.Context, functionName string, event interface{}) error {
+	if functionName == "" {
+		return fmt.Errorf("lambda function name is empty")
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lambda event: %w", err)
+	}
+
+	_, err = d.client.InvokeWithContext(ctx, &lambda.InvokeInput{
+		FunctionName:   aws.String(functionName),
+		InvocationType: aws.String(lambda.InvocationTypeEvent),
+		Payload:        payload,
+	})
+	if err != nil {
+		return fmt.Errorf("aws lambda error: %w", err)
+	}
+
+	return nil
+}
@@ -7,16 +7,36 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
 )
 
-type bayesDetector struct{}
+type bayesDetector struct {
+	client   *http.Client
+	endpoint string
+}
+
+// ModelVersion is the version of the model served by the API, which is updated when the model is retrained.
+const ModelVersion = "v1"
+
+const endpoint = "https://jxbe3mkwui.execute-api.ap-northeast-1.amazonaws.com/Prod/"
+
+// limits keeps requests small enough for the API, which runs on AWS Lambda behind API Gateway.
+var limits = sentiment.BatchLimits{
+	MaxItems:       25,
+	MaxTextBytes:   5000,
+	MaxRetries:     3,
+	RetryBaseDelay: 500 * time.Millisecond,
+}
 
 // NewBayesDetector creates Detector which implemented by Naive Bayes API.
 // https://github.com/hareku/sentiment-analysis-api
 func NewBayesDetector() sentiment.Detector {
-	return &bayesDetector{}
+	return sentiment.NewChunkedDetector(&bayesDetector{
+		client:   &http.Client{Timeout: 10 * time.Second},
+		endpoint: endpoint,
+	}, limits)
 }
 
 type response struct {
@@ -29,27 +49,22 @@ type score struct {
 	Neutral  float64
 }
 
-func (s *score) toOutput() sentiment.DetectOutput {
+// toOutput copies the score, so that outputs do not share pointers to the response.
+func (s score) toOutput() sentiment.DetectOutput {
+	positive, negative, neutral := s.Positive, s.Negative, s.Neutral
+	score := sentiment.Score{
+		Positive: &positive,
+		Negative: &negative,
+		Neutral:  &neutral,
+	}
 	return sentiment.DetectOutput{
-		Score: sentiment.Score{
-			Positive: &s.Positive,
-			Negative: &s.Negative,
-			Neutral:  &s.Neutral,
+		Score: score,
+		Label: sentiment.DefaultLabelPolicy.Label(score),
+		Detector: sentiment.DetectorInfo{
+			Name:         sentiment.DetectorBayes,
+			ModelVersion: ModelVersion,
 		},
-		Label: s.determineLabel(),
-	}
-}
-
-func (s *score) determineLabel() sentiment.Label {
-	if s.Positive > s.Negative && s.Positive > s.Neutral {
-		return sentiment.LabelPositive
-	}
-
-	if s.Negative > s.Positive && s.Negative > s.Neutral {
-		return sentiment.LabelNegative
 	}
-
-	return sentiment.LabelNeutral
 }
 
 func (d *bayesDetector) BatchDetect(ctx context.Context, textList []*string) ([]sentiment.DetectOutput, error) {
@@ -67,9 +82,19 @@ func (d *bayesDetector) BatchDetect(ctx context.Context, textList []*string) ([]
 		return nil, fmt.Errorf("failed to marshal json request body: %w", err)
 	}
 
-	resp, err := http.Post("https://jxbe3mkwui.execute-api.ap-northeast-1.amazonaws.com/Prod/", "application/json", bytes.NewBuffer(b))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.endpoint, bytes.NewBuffer(b))
 	if err != nil {
-		return nil, fmt.Errorf("http request error: %w", err)
+		return nil, fmt.Errorf("failed to create http request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("http request error: %w", err)
+		}
+		// Network errors and timeouts may be recovered by retrying.
+		return nil, fmt.Errorf("http request error: %w: %v", sentiment.ErrRetryable, err)
 	}
 	defer resp.Body.Close()
 
@@ -78,6 +103,9 @@ func (d *bayesDetector) BatchDetect(ctx context.Context, textList []*string) ([]
 		return nil, fmt.Errorf("response body reading error: %w", err)
 	}
 
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return nil, fmt.Errorf("status code is %d: %w, response body: %s", resp.StatusCode, sentiment.ErrRetryable, body)
+	}
 	if resp.StatusCode != 200 {
 		return nil, fmt.Errorf("status code is %d, response body: %s", resp.StatusCode, body)
 	}
@@ -89,8 +117,8 @@ func (d *bayesDetector) BatchDetect(ctx context.Context, textList []*string) ([]
 	}
 
 	output := []sentiment.DetectOutput{}
-	for _, score := range res.Result {
-		output = append(output, score.toOutput())
+	for i := range res.Result {
+		output = append(output, res.Result[i].toOutput())
 	}
 
 	return output, nil
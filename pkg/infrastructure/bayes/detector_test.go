@@ -2,13 +2,22 @@ package bayes
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"testing"
 
 	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
 )
 
+// Test_bayesDetector_BatchDetect calls the deployed detector, so it runs only when BAYES_LIVE_TEST is set.
 func Test_bayesDetector_BatchDetect(t *testing.T) {
+	if os.Getenv("BAYES_LIVE_TEST") == "" || testing.Short() {
+		t.Skip("BAYES_LIVE_TEST is not set, skipped the test which calls the deployed detector")
+	}
+
 	d := NewBayesDetector()
 	text1 := "これは最高に面白い映画です"
 	text2 := "これは最悪で最低の映画です"
@@ -27,3 +36,53 @@ func Test_bayesDetector_BatchDetect(t *testing.T) {
 		t.Errorf("%q is labeled as %s", text2, output[1].Label)
 	}
 }
+
+func Test_bayesDetector_BatchDetect_Stub(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result": [
+			{"Positive": 0.8, "Negative": 0.1, "Neutral": 0.1},
+			{"Positive": 0.1, "Negative": 0.7, "Neutral": 0.2},
+			{"Positive": 0.2, "Negative": 0.2, "Neutral": 0.6}
+		]}`)
+	}))
+	defer server.Close()
+
+	d := &bayesDetector{client: server.Client(), endpoint: server.URL}
+	texts := []string{"a", "b", "c"}
+	output, err := d.BatchDetect(context.Background(), []*string{&texts[0], &texts[1], &texts[2]})
+	if err != nil {
+		t.Fatalf("BatchDetect returned error: %s", err)
+	}
+
+	want := []struct {
+		positive float64
+		label    sentiment.Label
+	}{
+		{0.8, sentiment.LabelPositive},
+		{0.1, sentiment.LabelNegative},
+		{0.2, sentiment.LabelNeutral},
+	}
+	if len(output) != len(want) {
+		t.Fatalf("BatchDetect returned %d outputs, want %d", len(output), len(want))
+	}
+	for i, w := range want {
+		if *output[i].Score.Positive != w.positive {
+			t.Errorf("positive score of output %d = %v, want %v", i, *output[i].Score.Positive, w.positive)
+		}
+		if output[i].Label != w.label {
+			t.Errorf("label of output %d = %s, want %s", i, output[i].Label, w.label)
+		}
+	}
+
+	// Labels decided later by the policy must see the score of each output.
+	policy := sentiment.NewPolicyDetector(d, sentiment.LabelPolicy{})
+	output, err = policy.BatchDetect(context.Background(), []*string{&texts[0], &texts[1], &texts[2]})
+	if err != nil {
+		t.Fatalf("BatchDetect returned error: %s", err)
+	}
+	for i, w := range want {
+		if output[i].Label != w.label {
+			t.Errorf("label of output %d by the policy = %s, want %s", i, output[i].Label, w.label)
+		}
+	}
+}
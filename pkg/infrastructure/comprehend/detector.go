@@ -2,9 +2,13 @@ package comprehend
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/comprehend"
 	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
 )
@@ -13,47 +17,88 @@ type comprehendDetector struct {
 	client *comprehend.Comprehend
 }
 
+// limits are the quotas of BatchDetectSentiment API.
+// https://docs.aws.amazon.com/comprehend/latest/dg/guidelines-and-limits.html
+var limits = sentiment.BatchLimits{
+	MaxItems:       25,
+	MaxTextBytes:   5000,
+	MaxRetries:     3,
+	RetryBaseDelay: 500 * time.Millisecond,
+}
+
 // NewComprehendDetector creates Detector which implemented by AWS Comprehend.
 func NewComprehendDetector(client *comprehend.Comprehend) sentiment.Detector {
-	return &comprehendDetector{client}
+	return sentiment.NewChunkedDetector(&comprehendDetector{client}, limits)
+}
+
+// ModelVersion identifies the model of AWS Comprehend.
+// AWS Comprehend does not expose versions of its managed models, so it is the language of the model.
+const ModelVersion = comprehend.LanguageCodeJa
+
+var detectorInfo = sentiment.DetectorInfo{
+	Name:         sentiment.DetectorComprehend,
+	ModelVersion: ModelVersion,
 }
 
 func (d *comprehendDetector) BatchDetect(ctx context.Context, textList []*string) ([]sentiment.DetectOutput, error) {
 	output, err := d.client.BatchDetectSentimentWithContext(ctx, &comprehend.BatchDetectSentimentInput{
-		LanguageCode: aws.String(comprehend.LanguageCodeJa),
+		LanguageCode: aws.String(ModelVersion),
 		TextList:     textList,
 	})
+	if isRetryable(err) {
+		return nil, fmt.Errorf("aws comprehend error: %w: %v", sentiment.ErrRetryable, err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("aws comprehend error: %w", err)
 	}
 
-	res := []sentiment.DetectOutput{}
+	// Results and errors are identified by indexes of texts, and they are not ordered.
+	res := make([]sentiment.DetectOutput, len(textList))
+	done := make([]bool, len(textList))
 	for _, result := range output.ResultList {
-		neutral := *result.SentimentScore.Neutral + *result.SentimentScore.Mixed
-		res = append(res, sentiment.DetectOutput{
-			Score: sentiment.Score{
-				Positive: result.SentimentScore.Positive,
-				Negative: result.SentimentScore.Negative,
-				Neutral:  &neutral,
-			},
-			Label: determineLabel(result.Sentiment),
-		})
+		i := int(aws.Int64Value(result.Index))
+		if i < 0 || i >= len(textList) {
+			return nil, fmt.Errorf("aws comprehend returned result of unknown index %d", i)
+		}
+
+		score := sentiment.Score{
+			Positive: result.SentimentScore.Positive,
+			Negative: result.SentimentScore.Negative,
+			Neutral:  result.SentimentScore.Neutral,
+			Mixed:    result.SentimentScore.Mixed,
+		}
+		res[i] = sentiment.DetectOutput{
+			Score:    score,
+			Label:    sentiment.DefaultLabelPolicy.Label(score),
+			Detector: detectorInfo,
+		}
+		done[i] = true
+	}
+	for _, item := range output.ErrorList {
+		i := int(aws.Int64Value(item.Index))
+		if i < 0 || i >= len(textList) {
+			return nil, fmt.Errorf("aws comprehend returned error of unknown index %d", i)
+		}
+
+		res[i] = sentiment.NewFailedOutput(detectorInfo, fmt.Errorf("aws comprehend error: %s: %s", aws.StringValue(item.ErrorCode), aws.StringValue(item.ErrorMessage)))
+		done[i] = true
+	}
+	for i := range res {
+		if !done[i] {
+			res[i] = sentiment.NewFailedOutput(detectorInfo, errors.New("aws comprehend returned no result"))
+		}
 	}
 
 	return res, nil
 }
 
-func determineLabel(comprehendLabel *string) sentiment.Label {
-	switch *comprehendLabel {
-	case comprehend.SentimentTypePositive:
-		return sentiment.LabelPositive
-	case comprehend.SentimentTypeNegative:
-		return sentiment.LabelNegative
-	case comprehend.SentimentTypeMixed:
-		return sentiment.LabelNeutral
-	case comprehend.SentimentTypeNeutral:
-		return sentiment.LabelNeutral
-	default:
-		return sentiment.LabelUnknown
+func isRetryable(err error) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false
+	}
+	if aerr.Code() == comprehend.ErrCodeInternalServerException {
+		return true
 	}
+	return request.IsErrorThrottle(err) || request.IsErrorRetryable(err)
 }
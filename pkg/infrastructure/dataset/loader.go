@@ -0,0 +1,117 @@
+package dataset
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
+)
+
+// LoadLabeledTexts loads a labeled dataset from the CSV or JSONL file, which is selected by the extension.
+func LoadLabeledTexts(path string) ([]sentiment.LabeledText, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dataset: %w", err)
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ReadCSV(f)
+	case ".jsonl":
+		return ReadJSONL(f)
+	}
+	return nil, fmt.Errorf("unknown dataset format: %q", path)
+}
+
+// ReadCSV reads a labeled dataset from CSV which has "text" and "label" columns in its header.
+func ReadCSV(r io.Reader) ([]sentiment.LabeledText, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	textColumn, labelColumn := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "text":
+			textColumn = i
+		case "label":
+			labelColumn = i
+		}
+	}
+	if textColumn < 0 || labelColumn < 0 {
+		return nil, errors.New("csv header must have text and label columns")
+	}
+
+	res := []sentiment.LabeledText{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv: %w", err)
+		}
+
+		label, err := parseLabel(record[labelColumn])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		res = append(res, sentiment.LabeledText{Text: record[textColumn], Label: label})
+	}
+
+	return res, nil
+}
+
+// ReadJSONL reads a labeled dataset from JSON Lines, whose lines are like {"text": "...", "label": "POSITIVE"}.
+// Empty lines are ignored.
+func ReadJSONL(r io.Reader) ([]sentiment.LabeledText, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	res := []sentiment.LabeledText{}
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var item struct {
+			Text  string `json:"text"`
+			Label string `json:"label"`
+		}
+		err := json.Unmarshal(scanner.Bytes(), &item)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: json unmarshal error: %w", line, err)
+		}
+
+		label, err := parseLabel(item.Label)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		res = append(res, sentiment.LabeledText{Text: item.Text, Label: label})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read jsonl: %w", err)
+	}
+
+	return res, nil
+}
+
+// parseLabel parses a gold label case-insensitively.
+func parseLabel(s string) (sentiment.Label, error) {
+	label := sentiment.Label(strings.ToUpper(strings.TrimSpace(s)))
+	switch label {
+	case sentiment.LabelPositive, sentiment.LabelNegative, sentiment.LabelNeutral, sentiment.LabelMixed:
+		return label, nil
+	}
+	return "", fmt.Errorf("unknown label: %q", s)
+}
@@ -0,0 +1,32 @@
+package dataset
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
+)
+
+func TestReadCSV(t *testing.T) {
+	texts, err := ReadCSV(strings.NewReader("id,label,text\n1,positive,\"楽しい, 嬉しい\"\n2,NEGATIVE,最悪\n"))
+	if err != nil {
+		t.Fatalf("ReadCSV returned error: %s", err)
+	}
+	if len(texts) != 2 || texts[0].Text != "楽しい, 嬉しい" || texts[0].Label != sentiment.LabelPositive || texts[1].Label != sentiment.LabelNegative {
+		t.Errorf("unexpected texts: %+v", texts)
+	}
+
+	if _, err := ReadCSV(strings.NewReader("text,label\n楽しい,happy\n")); err == nil {
+		t.Errorf("ReadCSV returned no error for an unknown label")
+	}
+}
+
+func TestReadJSONL(t *testing.T) {
+	texts, err := ReadJSONL(strings.NewReader("{\"text\": \"普通\", \"label\": \"neutral\"}\n\n{\"text\": \"好きだけど嫌い\", \"label\": \"MIXED\"}\n"))
+	if err != nil {
+		t.Fatalf("ReadJSONL returned error: %s", err)
+	}
+	if len(texts) != 2 || texts[0].Label != sentiment.LabelNeutral || texts[1].Text != "好きだけど嫌い" || texts[1].Label != sentiment.LabelMixed {
+		t.Errorf("unexpected texts: %+v", texts)
+	}
+}
@@ -0,0 +1,70 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/guregu/dynamo"
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+	"github.com/hareku/emosearch-api/pkg/domain/repository"
+)
+
+type dynamoDBCollectionRunRepository struct {
+	dynamoDB dynamo.Table
+}
+
+// NewDynamoDBCollectionRunRepository creates CollectionRunRepository which is implemented by DynamoDB.
+func NewDynamoDBCollectionRunRepository(dynamoDB dynamo.Table) repository.CollectionRunRepository {
+	return &dynamoDBCollectionRunRepository{dynamoDB}
+}
+
+const (
+	// collectionRunSKLayout is the fixed width layout of the start time, so that runs are sorted by it.
+	collectionRunSKLayout = "2006-01-02T15:04:05.000000000Z"
+	collectionRunLifetime = 30 // days
+)
+
+type dynamoDBCollectionRun struct {
+	PK                 string
+	SK                 string
+	ExpirationUnixTime int64
+	*model.CollectionRun
+}
+
+func (r *dynamoDBCollectionRunRepository) Store(ctx context.Context, run *model.CollectionRun) error {
+	item := dynamoDBCollectionRun{
+		PK:                 fmt.Sprintf("SEARCH#%s", run.SearchID),
+		SK:                 fmt.Sprintf("RUN#%s", run.StartedAt.UTC().Format(collectionRunSKLayout)),
+		ExpirationUnixTime: run.StartedAt.AddDate(0, 0, collectionRunLifetime).Unix(),
+		CollectionRun:      run,
+	}
+
+	err := r.dynamoDB.Put(&item).RunWithContext(ctx)
+	if err != nil {
+		return fmt.Errorf("dynamo error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *dynamoDBCollectionRunRepository) ListBySearchID(ctx context.Context, searchID model.SearchID, limit int64) ([]*model.CollectionRun, error) {
+	var items []dynamoDBCollectionRun
+
+	err := r.dynamoDB.
+		Get("PK", fmt.Sprintf("SEARCH#%s", searchID)).
+		Range("SK", dynamo.BeginsWith, "RUN#").
+		Order(false).
+		Limit(limit).
+		AllWithContext(ctx, &items)
+	if err != nil && !errors.Is(err, dynamo.ErrNotFound) {
+		return nil, fmt.Errorf("dynamo error: %w", err)
+	}
+
+	runs := []*model.CollectionRun{}
+	for _, item := range items {
+		runs = append(runs, item.CollectionRun)
+	}
+
+	return runs, nil
+}
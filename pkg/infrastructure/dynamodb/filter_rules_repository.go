@@ -0,0 +1,66 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/guregu/dynamo"
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+	"github.com/hareku/emosearch-api/pkg/domain/repository"
+)
+
+type dynamoDBFilterRulesRepository struct {
+	dynamoDB dynamo.Table
+}
+
+// NewDynamoDBFilterRulesRepository creates FilterRulesRepository which is implemented by DynamoDB.
+func NewDynamoDBFilterRulesRepository(dynamoDB dynamo.Table) repository.FilterRulesRepository {
+	return &dynamoDBFilterRulesRepository{dynamoDB}
+}
+
+const (
+	configPK            = "CONFIG"
+	globalFilterRulesSK = "FILTER_RULES#GLOBAL"
+)
+
+type dynamoDBFilterRules struct {
+	PK        string
+	SK        string
+	UpdatedAt time.Time
+	*model.FilterRules
+}
+
+func (r *dynamoDBFilterRulesRepository) FindGlobal(ctx context.Context) (*model.FilterRules, error) {
+	var item dynamoDBFilterRules
+
+	err := r.dynamoDB.
+		Get("PK", configPK).
+		Range("SK", dynamo.Equal, globalFilterRulesSK).
+		OneWithContext(ctx, &item)
+	if errors.Is(err, dynamo.ErrNotFound) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dynamo error: %w", err)
+	}
+
+	return item.FilterRules, nil
+}
+
+func (r *dynamoDBFilterRulesRepository) UpdateGlobal(ctx context.Context, rules *model.FilterRules) error {
+	item := dynamoDBFilterRules{
+		PK:          configPK,
+		SK:          globalFilterRulesSK,
+		UpdatedAt:   time.Now(),
+		FilterRules: rules,
+	}
+
+	err := r.dynamoDB.Put(&item).RunWithContext(ctx)
+	if err != nil {
+		return fmt.Errorf("dynamo error: %w", err)
+	}
+
+	return nil
+}
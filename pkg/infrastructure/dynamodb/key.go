@@ -0,0 +1,7 @@
+package dynamodb
+
+// dynamoDBKey is the primary key of items in the table.
+type dynamoDBKey struct {
+	PK string
+	SK string
+}
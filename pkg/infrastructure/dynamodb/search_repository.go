@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/guregu/dynamo"
 	"github.com/hareku/emosearch-api/internal/uuid"
@@ -22,12 +23,13 @@ func NewDynamoDBSearchRepository(dynamoDB dynamo.Table) repository.SearchReposit
 
 // This value is used for "SearchIndex" GSI of DynamoDB.
 // May be split into random numbers in the future.
+// Only active searches have it, so that inactive searches are not listed in the index.
 const searchIndexPK = 1
 
 type dynamoDBSearch struct {
 	PK            string
 	SK            string
-	SearchIndexPK int64
+	SearchIndexPK int64 `dynamo:",omitempty"`
 	*model.Search
 }
 
@@ -112,10 +114,12 @@ func (r *dynamoDBSearchRepository) Create(ctx context.Context, search *model.Sea
 	search.SearchID = model.SearchID(searchID)
 
 	dynamoSearch := dynamoDBSearch{
-		PK:            fmt.Sprintf("USER#%s", search.UserID),
-		SK:            fmt.Sprintf("SEARCH#%s", search.SearchID),
-		SearchIndexPK: searchIndexPK,
-		Search:        search,
+		PK:     fmt.Sprintf("USER#%s", search.UserID),
+		SK:     fmt.Sprintf("SEARCH#%s", search.SearchID),
+		Search: search,
+	}
+	if search.IsActive() {
+		dynamoSearch.SearchIndexPK = searchIndexPK
 	}
 
 	err = r.dynamoDB.Put(&dynamoSearch).RunWithContext(ctx)
@@ -127,18 +131,198 @@ func (r *dynamoDBSearchRepository) Create(ctx context.Context, search *model.Sea
 	return nil
 }
 
+// Update updates the search. It returns repository.ErrNotFound if the search was deleted,
+// so that a partial search is not recreated by updates racing with the deletion.
 func (r *dynamoDBSearchRepository) Update(ctx context.Context, search *model.Search) error {
-	err := r.dynamoDB.Update("PK", fmt.Sprintf("USER#%s", search.UserID)).
+	u := r.dynamoDB.Update("PK", fmt.Sprintf("USER#%s", search.UserID)).
 		Range("SK", fmt.Sprintf("SEARCH#%s", search.SearchID)).
+		Set("Title", search.Title).
+		Set("Query", search.Query).
+		Set("Status", search.Status).
+		Set("PauseReason", search.PauseReason).
+		Set("LastSearchUpdatedAt", search.LastSearchUpdatedAt).
+		Set("NextSearchUpdateAt", search.NextSearchUpdateAt).
+		Set("NextSearchUpdateReason", search.NextSearchUpdateReason).
+		Set("IntervalMinutes", search.IntervalMinutes).
+		Set("AdaptiveInterval", search.AdaptiveInterval).
+		Set("EffectiveIntervalMinutes", search.EffectiveIntervalMinutes).
+		Set("SinceTweetID", search.SinceTweetID).
+		Set("CollectionCheckpoint", search.CollectionCheckpoint).
+		Set("DeletedTweetCount", search.DeletedTweetCount).
+		Set("Backfill", search.Backfill).
+		Set("FilterRules", search.FilterRules).
+		Set("SentimentDetector", search.SentimentDetector).
+		Set("UpdatedAt", search.UpdatedAt).
+		If("attribute_exists(PK)")
+
+	if search.IsActive() {
+		u.Set("SearchIndexPK", searchIndexPK)
+	} else {
+		u.Remove("SearchIndexPK")
+	}
+
+	err := u.RunWithContext(ctx)
+
+	if isConditionalCheckFailed(err) {
+		return repository.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("dynamo error: %w", err)
+	}
+
+	return nil
+}
+
+// setSearchSettings sets the fields of the search which are configured by its user.
+func setSearchSettings(u *dynamo.Update, search *model.Search) *dynamo.Update {
+	return u.
+		Set("Title", search.Title).
+		Set("IntervalMinutes", search.IntervalMinutes).
+		Set("AdaptiveInterval", search.AdaptiveInterval).
+		Set("FilterRules", search.FilterRules).
+		Set("SentimentDetector", search.SentimentDetector).
+		Set("UpdatedAt", search.UpdatedAt)
+}
+
+// UpdateSettings updates the settings of the search. Its collection progress is not written,
+// so that it is not reverted while the search is collected. It returns repository.ErrNotFound if the search was deleted.
+func (r *dynamoDBSearchRepository) UpdateSettings(ctx context.Context, search *model.Search) error {
+	u := r.dynamoDB.Update("PK", fmt.Sprintf("USER#%s", search.UserID)).
+		Range("SK", fmt.Sprintf("SEARCH#%s", search.SearchID))
+	err := setSearchSettings(u, search).
+		If("attribute_exists(PK)").
+		RunWithContext(ctx)
+
+	if isConditionalCheckFailed(err) {
+		return repository.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("dynamo error: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateQuery updates the settings and the query of the search, and resets its cursor, checkpoint, backfill and schedule.
+// It returns repository.ErrConflict if the query was changed from previousQuery, or the search was deleted.
+func (r *dynamoDBSearchRepository) UpdateQuery(ctx context.Context, search *model.Search, previousQuery string) error {
+	u := r.dynamoDB.Update("PK", fmt.Sprintf("USER#%s", search.UserID)).
+		Range("SK", fmt.Sprintf("SEARCH#%s", search.SearchID))
+	err := setSearchSettings(u, search).
 		Set("Query", search.Query).
+		Set("SinceTweetID", search.SinceTweetID).
+		Set("CollectionCheckpoint", search.CollectionCheckpoint).
+		Set("Backfill", search.Backfill).
+		Set("LastSearchUpdatedAt", search.LastSearchUpdatedAt).
+		Set("NextSearchUpdateAt", search.NextSearchUpdateAt).
+		Set("NextSearchUpdateReason", search.NextSearchUpdateReason).
+		Set("EffectiveIntervalMinutes", search.EffectiveIntervalMinutes).
+		If("Query = ?", previousQuery).
+		RunWithContext(ctx)
+
+	if isConditionalCheckFailed(err) {
+		return repository.ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("dynamo error: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateCollectionCursor updates the cursor and the checkpoint of the search unless its query was changed by others.
+func (r *dynamoDBSearchRepository) UpdateCollectionCursor(ctx context.Context, search *model.Search) error {
+	err := r.dynamoDB.Update("PK", fmt.Sprintf("USER#%s", search.UserID)).
+		Range("SK", fmt.Sprintf("SEARCH#%s", search.SearchID)).
+		Set("SinceTweetID", search.SinceTweetID).
+		Set("CollectionCheckpoint", search.CollectionCheckpoint).
+		If("Query = ?", search.Query).
+		RunWithContext(ctx)
+
+	if isConditionalCheckFailed(err) {
+		return repository.ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("dynamo error: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateSchedule updates the schedule of the search unless its query was changed by others.
+func (r *dynamoDBSearchRepository) UpdateSchedule(ctx context.Context, search *model.Search) error {
+	err := r.dynamoDB.Update("PK", fmt.Sprintf("USER#%s", search.UserID)).
+		Range("SK", fmt.Sprintf("SEARCH#%s", search.SearchID)).
 		Set("LastSearchUpdatedAt", search.LastSearchUpdatedAt).
 		Set("NextSearchUpdateAt", search.NextSearchUpdateAt).
+		Set("NextSearchUpdateReason", search.NextSearchUpdateReason).
+		Set("EffectiveIntervalMinutes", search.EffectiveIntervalMinutes).
+		If("Query = ?", search.Query).
+		RunWithContext(ctx)
+
+	if isConditionalCheckFailed(err) {
+		return repository.ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("dynamo error: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateBackfill updates the backfill progress of the search unless its query was changed by others.
+func (r *dynamoDBSearchRepository) UpdateBackfill(ctx context.Context, search *model.Search) error {
+	err := r.dynamoDB.Update("PK", fmt.Sprintf("USER#%s", search.UserID)).
+		Range("SK", fmt.Sprintf("SEARCH#%s", search.SearchID)).
+		Set("Backfill", search.Backfill).
+		If("Query = ?", search.Query).
 		RunWithContext(ctx)
 
+	if isConditionalCheckFailed(err) {
+		return repository.ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("dynamo error: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateStatus changes the status, the pause reason and the schedule of the search unless its status was changed by others.
+// Other fields are not written, so that the progress of running collections and backfills is kept.
+func (r *dynamoDBSearchRepository) UpdateStatus(ctx context.Context, search *model.Search, status model.SearchStatus, reason model.SearchPauseReason) error {
+	now := time.Now()
+	u := r.dynamoDB.Update("PK", fmt.Sprintf("USER#%s", search.UserID)).
+		Range("SK", fmt.Sprintf("SEARCH#%s", search.SearchID)).
+		Set("Status", status).
+		Set("PauseReason", reason).
+		Set("NextSearchUpdateAt", search.NextSearchUpdateAt).
+		Set("NextSearchUpdateReason", search.NextSearchUpdateReason).
+		Set("UpdatedAt", now)
+
+	// Searches created before SearchStatus was introduced have no status.
+	if search.Status == "" {
+		u.If("attribute_exists(PK) AND attribute_not_exists(Status)")
+	} else {
+		u.If("Status = ?", search.Status)
+	}
+	if status == model.SearchStatusActive {
+		u.Set("SearchIndexPK", searchIndexPK)
+	} else {
+		u.Remove("SearchIndexPK")
+	}
+
+	err := u.RunWithContext(ctx)
+	if isConditionalCheckFailed(err) {
+		return repository.ErrConflict
+	}
 	if err != nil {
 		return fmt.Errorf("dynamo error: %w", err)
 	}
 
+	search.Status = status
+	search.PauseReason = reason
+	search.UpdatedAt = now
 	return nil
 }
 
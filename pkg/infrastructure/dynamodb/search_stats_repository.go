@@ -0,0 +1,209 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/guregu/dynamo"
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+	"github.com/hareku/emosearch-api/pkg/domain/repository"
+	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
+)
+
+type dynamoDBSearchStatsRepository struct {
+	dynamoDB dynamo.Table
+}
+
+// NewDynamoDBSearchStatsRepository creates SearchStatsRepository which is implemented by DynamoDB.
+func NewDynamoDBSearchStatsRepository(dynamoDB dynamo.Table) repository.SearchStatsRepository {
+	return &dynamoDBSearchStatsRepository{dynamoDB}
+}
+
+const (
+	searchStatsTotalSK       = "STATS#TOTAL"
+	searchStatsDaySKPrefix   = "STATS#DAY#"
+	searchStatsLabelPrefix   = "LabelCount_"
+	searchStatsDateLayout    = model.DailySearchStatsDateLayout
+	searchStatsDailyLifetime = 7 // months
+)
+
+func (r *dynamoDBSearchStatsRepository) Find(ctx context.Context, searchID model.SearchID) (*model.SearchStats, error) {
+	var items []map[string]interface{}
+
+	err := r.dynamoDB.
+		Get("PK", fmt.Sprintf("SEARCH#%s", searchID)).
+		Range("SK", dynamo.BeginsWith, "STATS#").
+		AllWithContext(ctx, &items)
+	if err != nil {
+		return nil, fmt.Errorf("dynamo error: %w", err)
+	}
+
+	stats := &model.SearchStats{
+		LabelCounts: map[sentiment.Label]int64{},
+		Daily:       []model.DailySearchStats{},
+	}
+
+	for _, item := range items {
+		sk, _ := item["SK"].(string)
+		total, labels := decodeSearchStatsCounters(item)
+
+		if sk == searchStatsTotalSK {
+			stats.TotalCount = total
+			stats.LabelCounts = labels
+			continue
+		}
+
+		stats.Daily = append(stats.Daily, model.DailySearchStats{
+			Date:        strings.TrimPrefix(sk, searchStatsDaySKPrefix),
+			TotalCount:  total,
+			LabelCounts: labels,
+		})
+	}
+
+	return stats, nil
+}
+
+func decodeSearchStatsCounters(item map[string]interface{}) (int64, map[sentiment.Label]int64) {
+	var total int64
+	labels := map[sentiment.Label]int64{}
+
+	for k, v := range item {
+		n, ok := v.(float64)
+		if !ok {
+			continue
+		}
+		if k == "TotalCount" {
+			total = int64(n)
+		} else if strings.HasPrefix(k, searchStatsLabelPrefix) {
+			labels[sentiment.Label(strings.TrimPrefix(k, searchStatsLabelPrefix))] = int64(n)
+		}
+	}
+
+	return total, labels
+}
+
+type searchStatsCounter struct {
+	total  int64
+	labels map[sentiment.Label]int64
+}
+
+func (c *searchStatsCounter) add(label sentiment.Label) {
+	c.total++
+	c.labels[label]++
+}
+
+// move moves a tweet from a label to another one, and the total is not changed.
+func (c *searchStatsCounter) move(from sentiment.Label, to sentiment.Label) {
+	c.labels[from]--
+	c.labels[to]++
+}
+
+func (c *searchStatsCounter) isZero() bool {
+	if c.total != 0 {
+		return false
+	}
+	for _, n := range c.labels {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *searchStatsCounter) apply(u *dynamo.Update) *dynamo.Update {
+	if c.total != 0 {
+		u.Add("TotalCount", c.total)
+	}
+	for label, n := range c.labels {
+		if n != 0 {
+			u.Add(searchStatsLabelPrefix+string(label), n)
+		}
+	}
+	return u
+}
+
+// searchStatsCounters is the changes of the total and daily counters of a search.
+type searchStatsCounters struct {
+	total *searchStatsCounter
+	days  map[string]*searchStatsCounter
+}
+
+func newSearchStatsCounters() *searchStatsCounters {
+	return &searchStatsCounters{
+		total: &searchStatsCounter{labels: map[sentiment.Label]int64{}},
+		days:  map[string]*searchStatsCounter{},
+	}
+}
+
+func (s *searchStatsCounters) day(tweetCreatedAt time.Time) *searchStatsCounter {
+	date := tweetCreatedAt.UTC().Format(searchStatsDateLayout)
+	if _, ok := s.days[date]; !ok {
+		s.days[date] = &searchStatsCounter{labels: map[sentiment.Label]int64{}}
+	}
+	return s.days[date]
+}
+
+// updates builds updates of the changed counters.
+func (s *searchStatsCounters) updates(table dynamo.Table, searchID model.SearchID) []*dynamo.Update {
+	pk := fmt.Sprintf("SEARCH#%s", searchID)
+	updates := []*dynamo.Update{}
+	if !s.total.isZero() {
+		updates = append(updates, s.total.apply(table.Update("PK", pk).Range("SK", searchStatsTotalSK)))
+	}
+	for date, day := range s.days {
+		if day.isZero() {
+			continue
+		}
+		dayStart, _ := time.Parse(searchStatsDateLayout, date)
+		updates = append(updates, day.apply(table.Update("PK", pk).
+			Range("SK", searchStatsDaySKPrefix+date).
+			Set("ExpirationUnixTime", dayStart.AddDate(0, searchStatsDailyLifetime, 0).Unix())))
+	}
+	return updates
+}
+
+// buildSearchStatsUpdates builds updates which increment the counters of the search by the given tweets.
+// The given tweets must belong to the same search.
+func buildSearchStatsUpdates(table dynamo.Table, tweets []*model.Tweet) []*dynamo.Update {
+	if len(tweets) == 0 {
+		return nil
+	}
+
+	counters := newSearchStatsCounters()
+	for _, tweet := range tweets {
+		counters.total.add(tweet.SentimentLabel)
+		counters.day(tweet.TweetCreatedAt).add(tweet.SentimentLabel)
+	}
+
+	return counters.updates(table, tweets[0].SearchID)
+}
+
+// buildSearchStatsMoveUpdates builds updates which move the label counters of the search from the previous labels of the tweets.
+// The given tweets must belong to the same search.
+func buildSearchStatsMoveUpdates(table dynamo.Table, updates []*repository.TweetSentimentUpdate) []*dynamo.Update {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	counters := newSearchStatsCounters()
+	for _, u := range updates {
+		if u.PreviousLabel == u.Tweet.SentimentLabel {
+			continue
+		}
+		counters.total.move(u.PreviousLabel, u.Tweet.SentimentLabel)
+		counters.day(u.Tweet.TweetCreatedAt).move(u.PreviousLabel, u.Tweet.SentimentLabel)
+	}
+
+	return counters.updates(table, updates[0].Tweet.SearchID)
+}
+
+// countSearchStatsDays returns the number of distinct days (UTC) of the given tweets.
+func countSearchStatsDays(tweets []*model.Tweet) int {
+	days := map[string]struct{}{}
+	for _, tweet := range tweets {
+		days[tweet.TweetCreatedAt.UTC().Format(searchStatsDateLayout)] = struct{}{}
+	}
+	return len(days)
+}
@@ -0,0 +1,94 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/guregu/dynamo"
+	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
+)
+
+type dynamoDBSentimentCache struct {
+	dynamoDB dynamo.Table
+}
+
+// NewDynamoDBSentimentCache creates sentiment.Cache which is implemented by DynamoDB.
+func NewDynamoDBSentimentCache(dynamoDB dynamo.Table) sentiment.Cache {
+	return &dynamoDBSentimentCache{dynamoDB}
+}
+
+const (
+	sentimentCacheSK       = "OUTPUT"
+	sentimentCacheLifetime = 30 // days
+)
+
+type dynamoDBSentimentCacheItem struct {
+	PK                 string
+	SK                 string
+	ExpirationUnixTime int64
+	Output             sentiment.DetectOutput
+}
+
+func sentimentCachePK(key string) string {
+	return fmt.Sprintf("SENTIMENT_CACHE#%s", key)
+}
+
+func (c *dynamoDBSentimentCache) GetMulti(ctx context.Context, keys []string) (map[string]sentiment.DetectOutput, error) {
+	res := map[string]sentiment.DetectOutput{}
+	if len(keys) == 0 {
+		return res, nil
+	}
+
+	// Keys of BatchGetItem must be unique.
+	getKeys := []dynamo.Keyed{}
+	pks := map[string]string{}
+	for _, key := range keys {
+		pk := sentimentCachePK(key)
+		if _, ok := pks[pk]; ok {
+			continue
+		}
+		pks[pk] = key
+		getKeys = append(getKeys, dynamo.Keys{pk, sentimentCacheSK})
+	}
+
+	var items []dynamoDBSentimentCacheItem
+	err := c.dynamoDB.Batch("PK", "SK").Get(getKeys...).AllWithContext(ctx, &items)
+	if err != nil && !errors.Is(err, dynamo.ErrNotFound) {
+		return nil, fmt.Errorf("dynamo error: %w", err)
+	}
+
+	now := time.Now().Unix()
+	for _, item := range items {
+		// Expired items may remain until DynamoDB deletes them.
+		if item.ExpirationUnixTime < now {
+			continue
+		}
+		res[pks[item.PK]] = item.Output
+	}
+	return res, nil
+}
+
+func (c *dynamoDBSentimentCache) PutMulti(ctx context.Context, outputs map[string]sentiment.DetectOutput) error {
+	if len(outputs) == 0 {
+		return nil
+	}
+
+	expiration := time.Now().AddDate(0, 0, sentimentCacheLifetime).Unix()
+	items := []interface{}{}
+	for key, output := range outputs {
+		items = append(items, dynamoDBSentimentCacheItem{
+			PK:                 sentimentCachePK(key),
+			SK:                 sentimentCacheSK,
+			ExpirationUnixTime: expiration,
+			Output:             output,
+		})
+	}
+
+	_, err := c.dynamoDB.Batch("PK", "SK").Write().Put(items...).RunWithContext(ctx)
+	if err != nil {
+		return fmt.Errorf("dynamo error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsdynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	// maxTransactionConflictRetries is the number of retries of a transaction which conflicted with others,
+	// e.g. a collection and a backfill of the same search which update the same stats items.
+	maxTransactionConflictRetries = 5
+)
+
+// transactionConflictBaseDelay is the delay before the first retry of a conflicted transaction, which doubles by retries.
+var transactionConflictBaseDelay = 100 * time.Millisecond
+
+// runWriteTx runs the transaction of n items by run, which receives the indexes of the items to write,
+// and returns the indexes of the written items.
+// Items whose conditions failed are dropped and the others are written again,
+// and transactions which conflicted with other transactions are retried with backoff.
+func runWriteTx(ctx context.Context, n int, run func(items []int) error) ([]int, error) {
+	items := make([]int, n)
+	for i := range items {
+		items[i] = i
+	}
+
+	conflicts := 0
+	for len(items) > 0 {
+		err := run(items)
+		if err == nil {
+			return items, nil
+		}
+
+		var canceled *awsdynamodb.TransactionCanceledException
+		if !errors.As(err, &canceled) {
+			return nil, fmt.Errorf("dynamo error: %w", err)
+		}
+
+		remaining := []int{}
+		for i, item := range items {
+			if i < len(canceled.CancellationReasons) && aws.StringValue(canceled.CancellationReasons[i].Code) == "ConditionalCheckFailed" {
+				continue
+			}
+			remaining = append(remaining, item)
+		}
+
+		if isTransactionConflict(canceled) {
+			if conflicts >= maxTransactionConflictRetries {
+				return nil, fmt.Errorf("dynamo error: %d retries of conflicted transaction failed: %w", conflicts, err)
+			}
+			if err := sleepWithContext(ctx, transactionConflictBaseDelay<<conflicts); err != nil {
+				return nil, err
+			}
+			conflicts++
+		} else if len(remaining) == len(items) {
+			return nil, fmt.Errorf("dynamo error: %w", err)
+		}
+		items = remaining
+	}
+
+	return items, nil
+}
+
+// isTransactionConflict reports whether the transaction was canceled by a conflict with another transaction.
+func isTransactionConflict(canceled *awsdynamodb.TransactionCanceledException) bool {
+	for _, reason := range canceled.CancellationReasons {
+		if aws.StringValue(reason.Code) == "TransactionConflict" {
+			return true
+		}
+	}
+	return false
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,102 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsdynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// canceledTx returns the error of a transaction canceled by the codes of its items.
+func canceledTx(codes ...string) error {
+	reasons := []*awsdynamodb.CancellationReason{}
+	for _, code := range codes {
+		reasons = append(reasons, &awsdynamodb.CancellationReason{Code: aws.String(code)})
+	}
+	return &awsdynamodb.TransactionCanceledException{
+		Message_:            aws.String("Transaction cancelled"),
+		CancellationReasons: reasons,
+	}
+}
+
+func Test_runWriteTx(t *testing.T) {
+	defer func(delay time.Duration) { transactionConflictBaseDelay = delay }(transactionConflictBaseDelay)
+	transactionConflictBaseDelay = 0
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantRuns  [][]int
+		wantItems []int
+		wantErr   bool
+	}{
+		{
+			name:      "written",
+			errs:      []error{nil},
+			wantRuns:  [][]int{{0, 1, 2}},
+			wantItems: []int{0, 1, 2},
+		},
+		{
+			name:      "items whose conditions failed are dropped",
+			errs:      []error{canceledTx("None", "ConditionalCheckFailed", "None", "None"), nil},
+			wantRuns:  [][]int{{0, 1, 2}, {0, 2}},
+			wantItems: []int{0, 2},
+		},
+		{
+			// e.g. a backfill and a collection of the same search update the same stats item.
+			name:      "conflicted transactions are retried",
+			errs:      []error{canceledTx("None", "None", "None", "TransactionConflict"), canceledTx("None", "None", "None", "TransactionConflict"), nil},
+			wantRuns:  [][]int{{0, 1, 2}, {0, 1, 2}, {0, 1, 2}},
+			wantItems: []int{0, 1, 2},
+		},
+		{
+			name:      "conflicted transactions with failed conditions",
+			errs:      []error{canceledTx("ConditionalCheckFailed", "None", "None", "TransactionConflict"), nil},
+			wantRuns:  [][]int{{0, 1, 2}, {1, 2}},
+			wantItems: []int{1, 2},
+		},
+		{
+			name: "retries of conflicted transactions are limited",
+			errs: []error{
+				canceledTx("TransactionConflict"), canceledTx("TransactionConflict"), canceledTx("TransactionConflict"),
+				canceledTx("TransactionConflict"), canceledTx("TransactionConflict"), canceledTx("TransactionConflict"),
+			},
+			wantRuns: [][]int{{0, 1, 2}, {0, 1, 2}, {0, 1, 2}, {0, 1, 2}, {0, 1, 2}, {0, 1, 2}},
+			wantErr:  true,
+		},
+		{
+			name:     "other cancellations are not retried",
+			errs:     []error{canceledTx("None", "ValidationError", "None", "None")},
+			wantRuns: [][]int{{0, 1, 2}},
+			wantErr:  true,
+		},
+		{
+			name:     "other errors are not retried",
+			errs:     []error{errors.New("network error")},
+			wantRuns: [][]int{{0, 1, 2}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		runs := [][]int{}
+		items, err := runWriteTx(context.Background(), 3, func(items []int) error {
+			runs = append(runs, append([]int{}, items...))
+			return tt.errs[len(runs)-1]
+		})
+
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: runWriteTx() error = %v, wantErr %t", tt.name, err, tt.wantErr)
+		}
+		if !tt.wantErr && !reflect.DeepEqual(items, tt.wantItems) {
+			t.Errorf("%s: runWriteTx() = %v, want %v", tt.name, items, tt.wantItems)
+		}
+		if !reflect.DeepEqual(runs, tt.wantRuns) {
+			t.Errorf("%s: runs = %v, want %v", tt.name, runs, tt.wantRuns)
+		}
+	}
+}
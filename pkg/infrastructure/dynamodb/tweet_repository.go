@@ -4,23 +4,29 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/guregu/dynamo"
 	"github.com/hareku/emosearch-api/pkg/domain/model"
 	"github.com/hareku/emosearch-api/pkg/domain/repository"
 	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
+	"github.com/hareku/emosearch-api/pkg/domain/twitter"
 )
 
 type dynamoDBTweetRepository struct {
+	db       *dynamo.DB
 	dynamoDB dynamo.Table
 }
 
 // NewDynamoDBTweetRepository creates TweetRepository which is implemented by DynamoDB.
-func NewDynamoDBTweetRepository(dynamoDB dynamo.Table) repository.TweetRepository {
-	return &dynamoDBTweetRepository{dynamoDB}
+func NewDynamoDBTweetRepository(db *dynamo.DB, dynamoDB dynamo.Table) repository.TweetRepository {
+	return &dynamoDBTweetRepository{db, dynamoDB}
 }
 
+// maxTransactItems is the maximum number of items in a DynamoDB transaction.
+const maxTransactItems = 25
+
 type dynamoDBTweet struct {
 	PK                    string
 	SK                    string
@@ -33,47 +39,147 @@ func (d *dynamoDBTweet) NewTweetModel() *model.Tweet {
 }
 
 func (r *dynamoDBTweetRepository) Store(ctx context.Context, tweet *model.Tweet) error {
+	_, err := r.BatchStore(ctx, []*model.Tweet{tweet})
+	return err
+}
+
+// BatchStore stores tweets which are not stored yet, and increments the search stats by them.
+// Tweets and stats are written in transactions, so retrying with the same tweets does not count them twice.
+func (r *dynamoDBTweetRepository) BatchStore(ctx context.Context, tweets []*model.Tweet) (int, error) {
 	createdAt := time.Now()
-	tweet.CreatedAt = createdAt
-	tweet.UpdatedAt = createdAt
 
-	dtweet := dynamoDBTweet{
+	for _, tweet := range tweets {
+		tweet.CreatedAt = createdAt
+		tweet.UpdatedAt = createdAt
+	}
+
+	stored := 0
+	for _, chunk := range r.chunkForTransaction(tweets) {
+		n, err := r.storeWithStats(ctx, chunk)
+		stored += n
+		if err != nil {
+			return stored, err
+		}
+	}
+
+	return stored, nil
+}
+
+// chunkForTransaction splits tweets into chunks whose tweets and stats updates fit in a transaction.
+func (r *dynamoDBTweetRepository) chunkForTransaction(tweets []*model.Tweet) [][]*model.Tweet {
+	var chunks [][]*model.Tweet
+	var chunk []*model.Tweet
+
+	for _, tweet := range tweets {
+		chunk = append(chunk, tweet)
+		// A put per tweet, an update for total stats and an update per day.
+		if len(chunk) > 1 && len(chunk)+1+countSearchStatsDays(chunk) > maxTransactItems {
+			chunks = append(chunks, chunk[:len(chunk)-1])
+			chunk = []*model.Tweet{tweet}
+		}
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
+
+// storeWithStats stores the tweets which are not stored yet, and returns the number of stored tweets.
+func (r *dynamoDBTweetRepository) storeWithStats(ctx context.Context, tweets []*model.Tweet) (int, error) {
+	stored, err := runWriteTx(ctx, len(tweets), func(items []int) error {
+		chunk := []*model.Tweet{}
+		tx := r.db.WriteTx()
+		for _, i := range items {
+			chunk = append(chunk, tweets[i])
+			tx.Put(r.dynamoDB.Put(r.newDynamoDBTweet(tweets[i])).If("attribute_not_exists(SK)"))
+		}
+		for _, u := range buildSearchStatsUpdates(r.dynamoDB, chunk) {
+			tx.Update(u)
+		}
+		return tx.RunWithContext(ctx)
+	})
+	return len(stored), err
+}
+
+func (r *dynamoDBTweetRepository) newDynamoDBTweet(tweet *model.Tweet) *dynamoDBTweet {
+	return &dynamoDBTweet{
 		PK:                    fmt.Sprintf("SEARCH#%s", tweet.SearchID),
 		SK:                    fmt.Sprintf("TWEET#%d", tweet.TweetID),
 		TweetSentimentIndexPK: r.buildTweetSentimentIndexPK(tweet.SearchID, tweet.SentimentLabel),
 		Tweet:                 tweet,
 	}
+}
 
-	err := r.dynamoDB.Put(&dtweet).RunWithContext(ctx)
+// UpdateSentiments updates sentiments of tweets in transactions with the search stats, like BatchStore.
+func (r *dynamoDBTweetRepository) UpdateSentiments(ctx context.Context, updates []*repository.TweetSentimentUpdate) (int, error) {
+	updatedAt := time.Now()
+	updated := 0
 
-	if err != nil {
-		return fmt.Errorf("dynamo error: %w", err)
+	for _, chunk := range r.chunkSentimentUpdates(updates) {
+		for _, u := range chunk {
+			u.Tweet.UpdatedAt = updatedAt
+		}
+		n, err := r.updateSentimentsWithStats(ctx, chunk)
+		if err != nil {
+			return updated, err
+		}
+		updated += n
 	}
 
-	return nil
+	return updated, nil
 }
 
-func (r *dynamoDBTweetRepository) BatchStore(ctx context.Context, tweets []*model.Tweet) error {
-	createdAt := time.Now()
-	dynamoTweets := []interface{}{}
+// chunkSentimentUpdates splits updates into chunks whose tweets and stats updates fit in a transaction.
+func (r *dynamoDBTweetRepository) chunkSentimentUpdates(updates []*repository.TweetSentimentUpdate) [][]*repository.TweetSentimentUpdate {
+	var chunks [][]*repository.TweetSentimentUpdate
+	var chunk []*repository.TweetSentimentUpdate
+	var tweets []*model.Tweet
 
-	for _, tweet := range tweets {
-		tweet.CreatedAt = createdAt
-		tweet.UpdatedAt = createdAt
-		dynamoTweets = append(dynamoTweets, dynamoDBTweet{
-			PK:                    fmt.Sprintf("SEARCH#%s", tweet.SearchID),
-			SK:                    fmt.Sprintf("TWEET#%d", tweet.TweetID),
-			TweetSentimentIndexPK: r.buildTweetSentimentIndexPK(tweet.SearchID, tweet.SentimentLabel),
-			Tweet:                 tweet,
-		})
+	for _, u := range updates {
+		chunk = append(chunk, u)
+		tweets = append(tweets, u.Tweet)
+		if len(chunk) > 1 && len(chunk)+1+countSearchStatsDays(tweets) > maxTransactItems {
+			chunks = append(chunks, chunk[:len(chunk)-1])
+			chunk = []*repository.TweetSentimentUpdate{u}
+			tweets = []*model.Tweet{u.Tweet}
+		}
 	}
-
-	_, err := r.dynamoDB.Batch().Write().Put(dynamoTweets...).RunWithContext(ctx)
-	if err != nil {
-		return fmt.Errorf("dynamo error: %w", err)
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
 	}
 
-	return nil
+	return chunks
+}
+
+func (r *dynamoDBTweetRepository) updateSentimentsWithStats(ctx context.Context, updates []*repository.TweetSentimentUpdate) (int, error) {
+	// Tweets which were changed or deleted by others are skipped.
+	updated, err := runWriteTx(ctx, len(updates), func(items []int) error {
+		chunk := []*repository.TweetSentimentUpdate{}
+		tx := r.db.WriteTx()
+		for _, i := range items {
+			u := updates[i]
+			tweet := u.Tweet
+			chunk = append(chunk, u)
+			tx.Update(r.dynamoDB.Update("PK", fmt.Sprintf("SEARCH#%s", tweet.SearchID)).
+				Range("SK", fmt.Sprintf("TWEET#%d", tweet.TweetID)).
+				Set("NormalizedText", tweet.NormalizedText).
+				Set("SentimentScore", tweet.SentimentScore).
+				Set("SentimentLabel", tweet.SentimentLabel).
+				Set("SentimentDetector", tweet.SentimentDetector).
+				Set("SentimentConfidence", tweet.SentimentConfidence).
+				Set("Emotions", tweet.Emotions).
+				Set("DominantEmotion", tweet.DominantEmotion).
+				Set("TweetSentimentIndexPK", r.buildTweetSentimentIndexPK(tweet.SearchID, tweet.SentimentLabel)).
+				Set("UpdatedAt", tweet.UpdatedAt).
+				If("attribute_exists(SK) AND SentimentLabel = ?", u.PreviousLabel))
+		}
+		for _, u := range buildSearchStatsMoveUpdates(r.dynamoDB, chunk) {
+			tx.Update(u)
+		}
+		return tx.RunWithContext(ctx)
+	})
+	return len(updated), err
 }
 
 func (r *dynamoDBTweetRepository) List(ctx context.Context, input *repository.TweetRepositoryListInput) ([]model.Tweet, error) {
@@ -111,17 +217,127 @@ func (r *dynamoDBTweetRepository) buildListQuery(input *repository.TweetReposito
 
 	q.Order(false).Limit(input.Limit)
 
+	if input.DominantEmotion != nil {
+		q.Filter("DominantEmotion = ?", *input.DominantEmotion)
+	}
+
+	// The search partition also has items other than tweets, so the sort key must be bounded by "TWEET#".
 	if input.UntilID != 0 {
-		q.Range("SK", dynamo.Less, fmt.Sprintf("TWEET#%d", input.UntilID))
+		q.Range("SK", dynamo.Between, "TWEET#", fmt.Sprintf("TWEET#%d", input.UntilID-1))
+	} else {
+		q.Range("SK", dynamo.BeginsWith, "TWEET#")
 	}
 
 	return q
 }
 
+// DeleteBySearchID deletes up to limit items in the search partition, and returns the number of deleted tweets.
+// Tweets are deleted first, and the other items of the search (e.g. stats) are deleted after them.
+// done is true when all items of the search were deleted.
+func (r *dynamoDBTweetRepository) DeleteBySearchID(ctx context.Context, searchID model.SearchID, limit int64) (int, bool, error) {
+	var keys []dynamoDBKey
+
+	err := r.dynamoDB.
+		Get("PK", fmt.Sprintf("SEARCH#%s", searchID)).
+		Order(false).
+		Project("PK", "SK").
+		Consistent(true).
+		Limit(limit).
+		AllWithContext(ctx, &keys)
+	if err != nil && !errors.Is(err, dynamo.ErrNotFound) {
+		return 0, false, fmt.Errorf("dynamo error: %w", err)
+	}
+	if len(keys) == 0 {
+		return 0, true, nil
+	}
+
+	deleteKeys := []dynamo.Keyed{}
+	deletedTweets := 0
+	for _, key := range keys {
+		deleteKeys = append(deleteKeys, dynamo.Keys{key.PK, key.SK})
+		if strings.HasPrefix(key.SK, "TWEET#") {
+			deletedTweets++
+		}
+	}
+
+	_, err = r.dynamoDB.Batch("PK", "SK").Write().Delete(deleteKeys...).RunWithContext(ctx)
+	if err != nil {
+		return 0, false, fmt.Errorf("dynamo error: %w", err)
+	}
+
+	return deletedTweets, int64(len(keys)) < limit, nil
+}
+
+func (r *dynamoDBTweetRepository) AggregateSentimentTimeline(ctx context.Context, input *repository.TweetRepositoryAggregateSentimentTimelineInput) ([]model.SentimentTimelineBucket, error) {
+	if input.Bucket == model.TimelineBucketDay {
+		return r.aggregateDailySentimentTimeline(ctx, input)
+	}
+
+	timeline := model.NewSentimentTimeline(input.Bucket, input.From, input.To)
+
+	// Tweet IDs are ordered by their creation time, so the sort key can narrow down the range.
+	itr := r.dynamoDB.
+		Get("PK", fmt.Sprintf("SEARCH#%s", input.SearchID)).
+		Range("SK", dynamo.Between,
+			fmt.Sprintf("TWEET#%d", twitter.MinTweetIDAt(input.From)),
+			fmt.Sprintf("TWEET#%d", twitter.MinTweetIDAt(input.To))).
+		Project("TweetCreatedAt", "SentimentLabel", "SentimentScore", "DominantEmotion").
+		Iter()
+
+	var dTweet dynamoDBTweet
+	var count int64
+	for itr.NextWithContext(ctx, &dTweet) {
+		count++
+		if count > input.MaxTweets {
+			return nil, repository.ErrTooManyItems
+		}
+		if dTweet.Tweet != nil {
+			timeline.Add(dTweet.NewTweetModel())
+		}
+		dTweet = dynamoDBTweet{}
+	}
+	if err := itr.Err(); err != nil {
+		return nil, fmt.Errorf("dynamo error: %w", err)
+	}
+
+	return timeline.Buckets(), nil
+}
+
+// aggregateDailySentimentTimeline aggregates day buckets from the daily counters of SearchStats instead of tweets,
+// so its reads are bounded by the number of days. The counters are per day in UTC.
+func (r *dynamoDBTweetRepository) aggregateDailySentimentTimeline(ctx context.Context, input *repository.TweetRepositoryAggregateSentimentTimelineInput) ([]model.SentimentTimelineBucket, error) {
+	from, to := input.From.UTC(), input.To.UTC()
+	timeline := model.NewSentimentTimeline(input.Bucket, from, to)
+
+	var items []map[string]interface{}
+	err := r.dynamoDB.
+		Get("PK", fmt.Sprintf("SEARCH#%s", input.SearchID)).
+		Range("SK", dynamo.Between,
+			searchStatsDaySKPrefix+from.Format(searchStatsDateLayout),
+			searchStatsDaySKPrefix+to.Format(searchStatsDateLayout)).
+		AllWithContext(ctx, &items)
+	if err != nil {
+		return nil, fmt.Errorf("dynamo error: %w", err)
+	}
+
+	for _, item := range items {
+		sk, _ := item["SK"].(string)
+		total, labels := decodeSearchStatsCounters(item)
+		timeline.AddDailyStats(&model.DailySearchStats{
+			Date:        strings.TrimPrefix(sk, searchStatsDaySKPrefix),
+			TotalCount:  total,
+			LabelCounts: labels,
+		})
+	}
+
+	return timeline.Buckets(), nil
+}
+
 func (r *dynamoDBTweetRepository) LatestTweetID(ctx context.Context, searchID model.SearchID) (model.TweetID, error) {
 	var dynamoTweet dynamoDBTweet
 	err := r.dynamoDB.
 		Get("PK", fmt.Sprintf("SEARCH#%s", searchID)).
+		Range("SK", dynamo.BeginsWith, "TWEET#").
 		Limit(1).
 		Order(false).
 		OneWithContext(ctx, &dynamoTweet)
@@ -0,0 +1,70 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/guregu/dynamo"
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+	"github.com/hareku/emosearch-api/pkg/domain/repository"
+)
+
+type dynamoDBTweetRescoringRepository struct {
+	dynamoDB dynamo.Table
+}
+
+// NewDynamoDBTweetRescoringRepository creates TweetRescoringRepository which is implemented by DynamoDB.
+func NewDynamoDBTweetRescoringRepository(dynamoDB dynamo.Table) repository.TweetRescoringRepository {
+	return &dynamoDBTweetRescoringRepository{dynamoDB}
+}
+
+const (
+	// tweetRescoringSKLayout is the fixed width layout of the start time, so that reports are sorted by it.
+	tweetRescoringSKLayout = collectionRunSKLayout
+	tweetRescoringLifetime = 90 // days
+)
+
+type dynamoDBTweetRescoring struct {
+	PK                 string
+	SK                 string
+	ExpirationUnixTime int64
+	*model.TweetRescoring
+}
+
+func (r *dynamoDBTweetRescoringRepository) Store(ctx context.Context, rescoring *model.TweetRescoring) error {
+	item := dynamoDBTweetRescoring{
+		PK:                 fmt.Sprintf("SEARCH#%s", rescoring.SearchID),
+		SK:                 fmt.Sprintf("RESCORE#%s", rescoring.StartedAt.UTC().Format(tweetRescoringSKLayout)),
+		ExpirationUnixTime: rescoring.StartedAt.AddDate(0, 0, tweetRescoringLifetime).Unix(),
+		TweetRescoring:     rescoring,
+	}
+
+	err := r.dynamoDB.Put(&item).RunWithContext(ctx)
+	if err != nil {
+		return fmt.Errorf("dynamo error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *dynamoDBTweetRescoringRepository) ListBySearchID(ctx context.Context, searchID model.SearchID, limit int64) ([]*model.TweetRescoring, error) {
+	var items []dynamoDBTweetRescoring
+
+	err := r.dynamoDB.
+		Get("PK", fmt.Sprintf("SEARCH#%s", searchID)).
+		Range("SK", dynamo.BeginsWith, "RESCORE#").
+		Order(false).
+		Limit(limit).
+		AllWithContext(ctx, &items)
+	if err != nil && !errors.Is(err, dynamo.ErrNotFound) {
+		return nil, fmt.Errorf("dynamo error: %w", err)
+	}
+
+	rescorings := []*model.TweetRescoring{}
+	for _, item := range items {
+		rescorings = append(rescorings, item.TweetRescoring)
+	}
+
+	return rescorings, nil
+}
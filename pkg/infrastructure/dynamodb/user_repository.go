@@ -3,6 +3,7 @@ package dynamodb
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/guregu/dynamo"
 	"github.com/hareku/emosearch-api/pkg/domain/model"
@@ -22,9 +23,11 @@ type dynamoDBUser struct {
 	PK string
 	SK string
 
-	UserID                   model.UserID `dynamo:"UserID"`
-	TwitterAccessToken       string       `dynamo:"TwitterAccessToken"`
-	TwitterAccessTokenSecret string       `dynamo:"TwitterAccessTokenSecret"`
+	UserID                    model.UserID `dynamo:"UserID"`
+	TwitterAccessToken        string       `dynamo:"TwitterAccessToken"`
+	TwitterAccessTokenSecret  string       `dynamo:"TwitterAccessTokenSecret"`
+	Quota                     *model.Quota `dynamo:"Quota,omitempty"`
+	ReauthorizationRequiredAt *time.Time   `dynamo:"ReauthorizationRequiredAt,omitempty"`
 }
 
 func (r *dynamoDbUserRepository) Create(ctx context.Context, user *model.User) error {
@@ -63,10 +66,55 @@ func (r *dynamoDbUserRepository) FindByID(ctx context.Context, userID model.User
 	}
 
 	user := &model.User{
-		UserID:                   dbUser.UserID,
-		TwitterAccessToken:       dbUser.TwitterAccessToken,
-		TwitterAccessTokenSecret: dbUser.TwitterAccessTokenSecret,
+		UserID:                    dbUser.UserID,
+		TwitterAccessToken:        dbUser.TwitterAccessToken,
+		TwitterAccessTokenSecret:  dbUser.TwitterAccessTokenSecret,
+		Quota:                     dbUser.Quota,
+		ReauthorizationRequiredAt: dbUser.ReauthorizationRequiredAt,
 	}
 
 	return user, nil
 }
+
+func (r *dynamoDbUserRepository) UpdateQuota(ctx context.Context, userID model.UserID, quota *model.Quota) error {
+	update := r.dynamoDB.
+		Update("PK", fmt.Sprintf("USER#%s", userID)).
+		Range("SK", fmt.Sprintf("PROFILE#%s", userID)).
+		If("attribute_exists(PK)")
+
+	if quota != nil {
+		update.Set("Quota", quota)
+	} else {
+		update.Remove("Quota")
+	}
+
+	err := update.RunWithContext(ctx)
+	if isConditionalCheckFailed(err) {
+		return repository.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("DynamoDB error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *dynamoDbUserRepository) UpdateAuthorization(ctx context.Context, user *model.User) error {
+	err := r.dynamoDB.
+		Update("PK", fmt.Sprintf("USER#%s", user.UserID)).
+		Range("SK", fmt.Sprintf("PROFILE#%s", user.UserID)).
+		Set("TwitterAccessToken", user.TwitterAccessToken).
+		Set("TwitterAccessTokenSecret", user.TwitterAccessTokenSecret).
+		Set("ReauthorizationRequiredAt", user.ReauthorizationRequiredAt).
+		If("attribute_exists(PK)").
+		RunWithContext(ctx)
+
+	if isConditionalCheckFailed(err) {
+		return repository.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("DynamoDB error: %w", err)
+	}
+
+	return nil
+}
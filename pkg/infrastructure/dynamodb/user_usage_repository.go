@@ -0,0 +1,68 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/guregu/dynamo"
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+	"github.com/hareku/emosearch-api/pkg/domain/repository"
+)
+
+type dynamoDBUserUsageRepository struct {
+	dynamoDB dynamo.Table
+}
+
+// NewDynamoDBUserUsageRepository creates UserUsageRepository which is implemented by DynamoDB.
+func NewDynamoDBUserUsageRepository(dynamoDB dynamo.Table) repository.UserUsageRepository {
+	return &dynamoDBUserUsageRepository{dynamoDB}
+}
+
+// userUsageLifetime is the number of months to keep usage items.
+const userUsageLifetime = 13
+
+type dynamoDBUserUsage struct {
+	PK               string
+	SK               string
+	StoredTweetCount int64
+}
+
+func (r *dynamoDBUserUsageRepository) FindMonthly(ctx context.Context, userID model.UserID, at time.Time) (*model.UserUsage, error) {
+	month := model.UserUsageMonth(at)
+	var item dynamoDBUserUsage
+
+	err := r.dynamoDB.
+		Get("PK", fmt.Sprintf("USER#%s", userID)).
+		Range("SK", dynamo.Equal, fmt.Sprintf("USAGE#%s", month)).
+		OneWithContext(ctx, &item)
+	if errors.Is(err, dynamo.ErrNotFound) {
+		return &model.UserUsage{Month: month}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dynamo error: %w", err)
+	}
+
+	return &model.UserUsage{
+		Month:            month,
+		StoredTweetCount: item.StoredTweetCount,
+	}, nil
+}
+
+func (r *dynamoDBUserUsageRepository) AddStoredTweets(ctx context.Context, userID model.UserID, at time.Time, count int64) error {
+	month := model.UserUsageMonth(at)
+	monthStart, _ := time.Parse("2006-01", month)
+
+	err := r.dynamoDB.
+		Update("PK", fmt.Sprintf("USER#%s", userID)).
+		Range("SK", fmt.Sprintf("USAGE#%s", month)).
+		Add("StoredTweetCount", count).
+		Set("ExpirationUnixTime", monthStart.AddDate(0, userUsageLifetime, 0).Unix()).
+		RunWithContext(ctx)
+	if err != nil {
+		return fmt.Errorf("dynamo error: %w", err)
+	}
+
+	return nil
+}
@@ -33,12 +33,16 @@ func (fa *firebaseAuthenticator) Authenticate(ctx context.Context) (context.Cont
 		return nil, fmt.Errorf("authentication failed: %w", err)
 	}
 
-	userID, err := fa.checkIDToken(ctx, idToken)
+	token, err := fa.checkIDToken(ctx, idToken)
 	if err != nil {
 		return nil, fmt.Errorf("authentication failed: %w", err)
 	}
 
-	return ctxval.SetUserID(ctx, userID), nil
+	// Admins are granted by the custom claim of Firebase-Authentication.
+	admin, _ := token.Claims["admin"].(bool)
+
+	ctx = ctxval.SetUserID(ctx, model.UserID(token.UID))
+	return ctxval.SetAdmin(ctx, admin), nil
 }
 
 func (fa *firebaseAuthenticator) IsAuthenticated(ctx context.Context) bool {
@@ -55,6 +59,10 @@ func (fa *firebaseAuthenticator) UserID(ctx context.Context) (model.UserID, erro
 	return userID, nil
 }
 
+func (fa *firebaseAuthenticator) IsAdmin(ctx context.Context) bool {
+	return ctxval.IsAdmin(ctx)
+}
+
 func (fa *firebaseAuthenticator) resolveIDToken(authHeader string) (string, error) {
 	if !strings.HasPrefix(authHeader, "Bearer ") {
 		return "", fmt.Errorf("authorization type should be %q", "Bearer")
@@ -63,13 +71,13 @@ func (fa *firebaseAuthenticator) resolveIDToken(authHeader string) (string, erro
 	return strings.Replace(authHeader, "Bearer ", "", 1), nil
 }
 
-func (fa *firebaseAuthenticator) checkIDToken(ctx context.Context, idToken string) (model.UserID, error) {
+func (fa *firebaseAuthenticator) checkIDToken(ctx context.Context, idToken string) (*firebase_auth.Token, error) {
 	token, err := fa.firebaseAuth.VerifyIDToken(ctx, idToken)
 	if err != nil {
-		return "", fmt.Errorf("firebase-authentication could not verify ID token: %w", err)
+		return nil, fmt.Errorf("firebase-authentication could not verify ID token: %w", err)
 	}
 
-	return model.UserID(token.UID), nil
+	return token, nil
 }
 
 func (fa *firebaseAuthenticator) ListUserID(ctx context.Context, pageToken string) ([]model.UserID, string, error) {
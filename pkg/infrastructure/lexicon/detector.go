@@ -0,0 +1,192 @@
+package lexicon
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
+)
+
+type lexiconDetector struct {
+	maxWordLen        int
+	maxEmotionWordLen int
+	maxIntensifierLen int
+}
+
+// NewLexiconDetector creates Detector which is implemented by the bundled Japanese polarity and emotion lexicons.
+// It runs in process, so it does not require any network access.
+func NewLexiconDetector() sentiment.Detector {
+	d := &lexiconDetector{}
+	for word := range polarities {
+		if n := len([]rune(word)); n > d.maxWordLen {
+			d.maxWordLen = n
+		}
+	}
+	for word := range emotionWords {
+		if n := len([]rune(word)); n > d.maxEmotionWordLen {
+			d.maxEmotionWordLen = n
+		}
+	}
+	for word := range intensifiers {
+		if n := len([]rune(word)); n > d.maxIntensifierLen {
+			d.maxIntensifierLen = n
+		}
+	}
+	return d
+}
+
+// neutralWeight is the weight of neutral, which is compared with the sum of polarities of words.
+// A text which contains only a weak word, like "いい", is neutral.
+const neutralWeight = 0.5
+
+func (d *lexiconDetector) BatchDetect(ctx context.Context, textList []*string) ([]sentiment.DetectOutput, error) {
+	outputs := []sentiment.DetectOutput{}
+	for _, text := range textList {
+		outputs = append(outputs, d.detect(*text))
+	}
+	return outputs, nil
+}
+
+func (d *lexiconDetector) detect(text string) sentiment.DetectOutput {
+	text = strings.ToLower(text)
+
+	var positive, negative float64
+	for _, polarity := range d.polarities(text) {
+		if polarity > 0 {
+			positive += polarity
+		} else {
+			negative -= polarity
+		}
+	}
+
+	total := positive + negative + neutralWeight
+	positive /= total
+	negative /= total
+	neutral := neutralWeight / total
+
+	score := sentiment.Score{
+		Positive: &positive,
+		Negative: &negative,
+		Neutral:  &neutral,
+	}
+	return sentiment.DetectOutput{
+		Score:    score,
+		Label:    sentiment.DefaultLabelPolicy.Label(score),
+		Emotions: d.emotions(text),
+		Detector: sentiment.DetectorInfo{
+			Name:         sentiment.DetectorLexicon,
+			ModelVersion: ModelVersion,
+		},
+	}
+}
+
+// polarities returns the polarities of words in the text, which are adjusted by intensifiers and negations.
+// Words are matched by the longest match from the head of the text.
+func (d *lexiconDetector) polarities(text string) []float64 {
+	rs := []rune(text)
+	res := []float64{}
+
+	for i := 0; i < len(rs); {
+		word, polarity := d.longestWord(rs[i:])
+		if word == 0 {
+			i++
+			continue
+		}
+
+		polarity *= d.intensity(rs[:i])
+		if isNegated(rs[i+word:]) {
+			polarity *= negationFactor
+		}
+		res = append(res, polarity)
+		i += word
+	}
+
+	return res
+}
+
+// longestWord returns the length and the polarity of the longest word at the head of rs.
+func (d *lexiconDetector) longestWord(rs []rune) (int, float64) {
+	for n := min(d.maxWordLen, len(rs)); n > 0; n-- {
+		if polarity, ok := polarities[string(rs[:n])]; ok {
+			return n, polarity
+		}
+	}
+	return 0, 0
+}
+
+// emotions returns the shares of emotion words in the text, which are weighted by intensifiers.
+// Negated words do not express their emotions (e.g. "怖くない"), and a text without emotion words has no emotions.
+func (d *lexiconDetector) emotions(text string) *sentiment.EmotionScore {
+	rs := []rune(text)
+	weights := map[sentiment.Emotion]float64{}
+	var total float64
+
+	for i := 0; i < len(rs); {
+		word, emotion := d.longestEmotionWord(rs[i:])
+		if word == 0 {
+			i++
+			continue
+		}
+
+		if !isNegated(rs[i+word:]) {
+			weight := d.intensity(rs[:i])
+			weights[emotion] += weight
+			total += weight
+		}
+		i += word
+	}
+
+	score := &sentiment.EmotionScore{}
+	for emotion, weight := range weights {
+		score.Add(emotion, weight/total)
+	}
+	return score
+}
+
+// longestEmotionWord returns the length and the emotion of the longest emotion word at the head of rs.
+func (d *lexiconDetector) longestEmotionWord(rs []rune) (int, sentiment.Emotion) {
+	for n := min(d.maxEmotionWordLen, len(rs)); n > 0; n-- {
+		if emotion, ok := emotionWords[string(rs[:n])]; ok {
+			return n, emotion
+		}
+	}
+	return 0, ""
+}
+
+// intensity returns the multiplier of the intensifier which precedes the word.
+func (d *lexiconDetector) intensity(preceding []rune) float64 {
+	for n := min(d.maxIntensifierLen, len(preceding)); n > 0; n-- {
+		if m, ok := intensifiers[string(preceding[len(preceding)-n:])]; ok {
+			return m
+		}
+	}
+	return 1
+}
+
+// isNegated reports whether the characters after a word contain negations before the end of the phrase.
+func isNegated(following []rune) bool {
+	end := 0
+	for end < len(following) && end < negationWindow && !isPhraseBreak(following[end]) {
+		end++
+	}
+
+	window := string(following[:end])
+	for _, negation := range negations {
+		if strings.Contains(window, negation) {
+			return true
+		}
+	}
+	return false
+}
+
+func isPhraseBreak(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsPunct(r) || strings.ContainsRune("、。！？!?　", r)
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,90 @@
+package lexicon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
+)
+
+func Test_lexiconDetector_BatchDetect(t *testing.T) {
+	tests := []struct {
+		text string
+		want sentiment.Label
+	}{
+		{"これは最高に面白い映画です", sentiment.LabelPositive},
+		{"これは最悪で最低の映画です", sentiment.LabelNegative},
+		{"今日は電車で会社に行きます", sentiment.LabelNeutral},
+		{"全然楽しくなかった", sentiment.LabelNegative},
+		{"この曲は好きじゃない。", sentiment.LabelNegative},
+		{"つまらない話だった", sentiment.LabelNegative},
+		{"悪くない", sentiment.LabelNeutral},
+		{"いい天気", sentiment.LabelNeutral},
+		{"とてもいい天気", sentiment.LabelPositive},
+	}
+
+	d := NewLexiconDetector()
+	textList := []*string{}
+	for i := range tests {
+		textList = append(textList, &tests[i].text)
+	}
+
+	outputs, err := d.BatchDetect(context.Background(), textList)
+	if err != nil {
+		t.Fatalf("BatchDetect returned error: %s", err)
+	}
+	if len(outputs) != len(tests) {
+		t.Fatalf("BatchDetect returned %d outputs, want %d", len(outputs), len(tests))
+	}
+
+	for i, tt := range tests {
+		if outputs[i].Label != tt.want {
+			t.Errorf("%q is labeled as %s, want %s", tt.text, outputs[i].Label, tt.want)
+		}
+		if outputs[i].Detector.Name != sentiment.DetectorLexicon {
+			t.Errorf("detector of %q is %q", tt.text, outputs[i].Detector.Name)
+		}
+	}
+}
+
+func Test_lexiconDetector_Intensity(t *testing.T) {
+	d := NewLexiconDetector().(*lexiconDetector)
+
+	plain := d.detect("楽しい")
+	intensified := d.detect("めっちゃ楽しい")
+	if *intensified.Score.Positive <= *plain.Score.Positive {
+		t.Errorf("intensified positive score %f is not greater than %f", *intensified.Score.Positive, *plain.Score.Positive)
+	}
+
+	weakened := d.detect("少し楽しい")
+	if *weakened.Score.Positive >= *plain.Score.Positive {
+		t.Errorf("weakened positive score %f is not less than %f", *weakened.Score.Positive, *plain.Score.Positive)
+	}
+}
+
+func Test_lexiconDetector_Emotions(t *testing.T) {
+	tests := []struct {
+		text string
+		want sentiment.Emotion
+	}{
+		{"合格できて本当に嬉しい", sentiment.EmotionJoy},
+		{"対応が遅すぎてイライラする", sentiment.EmotionAnger},
+		{"最終回で泣いた", sentiment.EmotionSadness},
+		{"夜道が怖い", sentiment.EmotionFear},
+		{"まさかの結末にびっくり", sentiment.EmotionSurprise},
+		{"この味は気持ち悪い", sentiment.EmotionDisgust},
+		{"今日は電車で会社に行きます", ""},
+		{"全然怖くない", ""},
+	}
+
+	d := NewLexiconDetector().(*lexiconDetector)
+	for _, tt := range tests {
+		output := d.detect(tt.text)
+		if output.Emotions == nil {
+			t.Fatalf("emotions of %q are nil", tt.text)
+		}
+		if got := output.Emotions.Dominant(); got != tt.want {
+			t.Errorf("dominant emotion of %q is %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package lexicon
+
+import "github.com/hareku/emosearch-api/pkg/domain/sentiment"
+
+// ModelVersion is the version of the bundled lexicon, which is updated when entries are changed.
+const ModelVersion = "ja-v2"
+
+// polarities is the bundled Japanese polarity lexicon, which maps words to polarities in [-1, 1].
+// Entries are stems without inflected endings, so that they match every inflection of the word
+// (e.g. "楽し" matches "楽しい", "楽しかった" and "楽しくない").
+// Stems which end with "な" of negative adjectives (e.g. "つまらな") include it, so that it is not taken as negation.
+var polarities = map[string]float64{
+	// positive
+	"最高":    1.0,
+	"素晴らし":  1.0,
+	"すばらし":  1.0,
+	"大好き":   1.0,
+	"幸せ":    0.9,
+	"しあわせ":  0.9,
+	"感動":    0.9,
+	"嬉し":    0.9,
+	"うれし":   0.9,
+	"楽し":    0.8,
+	"たのし":   0.8,
+	"面白":    0.8,
+	"おもしろ":  0.8,
+	"好き":    0.8,
+	"すき":    0.6,
+	"愛し":    0.8,
+	"ありがと":  0.7,
+	"ありがとう": 0.7,
+	"感謝":    0.7,
+	"美味し":   0.8,
+	"おいし":   0.8,
+	"うま":    0.5,
+	"可愛":    0.8,
+	"かわい":   0.8,
+	"綺麗":    0.7,
+	"きれい":   0.7,
+	"素敵":    0.8,
+	"すてき":   0.8,
+	"良い":    0.6,
+	"良かった":  0.7,
+	"よかった":  0.7,
+	"いい":    0.4,
+	"最強":    0.7,
+	"満足":    0.7,
+	"安心":    0.6,
+	"笑":     0.4,
+	"喜":     0.7,
+	"助か":    0.6,
+	"成功":    0.6,
+	"優し":    0.7,
+	"やさし":   0.7,
+	"癒":     0.7,
+	"快適":    0.6,
+	"楽しみ":   0.8,
+	"ワクワク":  0.7,
+	"わくわく":  0.7,
+	"おめでと":  0.8,
+	"頑張":    0.3,
+	"元気":    0.5,
+	"尊い":    0.8,
+	"エモい":   0.5,
+	"😊":     0.8,
+	"😄":     0.8,
+	"😍":     0.9,
+	"🥰":     0.9,
+	"👍":     0.6,
+	"❤":     0.7,
+
+	// negative
+	"最悪":   -1.0,
+	"最低":   -1.0,
+	"大嫌い":  -1.0,
+	"嫌い":   -0.8,
+	"きらい":  -0.8,
+	"嫌":    -0.6,
+	"いや":   -0.3,
+	"悲し":   -0.9,
+	"かなし":  -0.9,
+	"寂し":   -0.7,
+	"さみし":  -0.7,
+	"辛":    -0.7,
+	"つら":   -0.7,
+	"苦し":   -0.8,
+	"痛":    -0.6,
+	"怖":    -0.7,
+	"こわ":   -0.6,
+	"不安":   -0.7,
+	"心配":   -0.5,
+	"残念":   -0.7,
+	"ひど":   -0.8,
+	"酷":    -0.8,
+	"つまらな": -0.8,
+	"くだらな": -0.7,
+	"情けな":  -0.7,
+	"申し訳な": -0.4,
+	"危な":   -0.5,
+	"むかつ":  -0.9,
+	"ムカつ":  -0.9,
+	"腹立":   -0.9,
+	"イライラ": -0.8,
+	"いらいら": -0.8,
+	"うざ":   -0.8,
+	"ウザ":   -0.8,
+	"疲れ":   -0.5,
+	"しんど":  -0.6,
+	"だる":   -0.5,
+	"ダル":   -0.5,
+	"まずい":  -0.6,
+	"不味":   -0.7,
+	"悪い":   -0.6,
+	"悪":    -0.5,
+	"失敗":   -0.6,
+	"後悔":   -0.7,
+	"絶望":   -1.0,
+	"死にた":  -1.0,
+	"泣":    -0.5,
+	"不満":   -0.7,
+	"迷惑":   -0.7,
+	"気持ち悪": -0.9,
+	"キモ":   -0.8,
+	"きも":   -0.6,
+	"憂鬱":   -0.8,
+	"不快":   -0.8,
+	"😢":    -0.8,
+	"😭":    -0.6,
+	"😡":    -0.9,
+	"😠":    -0.8,
+	"👎":    -0.6,
+}
+
+// emotionWords is the bundled Japanese emotion lexicon, which maps words to emotions.
+// Entries are stems like polarities, and words may be in both lexicons.
+var emotionWords = map[string]sentiment.Emotion{
+	// joy
+	"嬉し":   sentiment.EmotionJoy,
+	"うれし":  sentiment.EmotionJoy,
+	"楽し":   sentiment.EmotionJoy,
+	"たのし":  sentiment.EmotionJoy,
+	"幸せ":   sentiment.EmotionJoy,
+	"しあわせ": sentiment.EmotionJoy,
+	"喜":    sentiment.EmotionJoy,
+	"最高":   sentiment.EmotionJoy,
+	"大好き":  sentiment.EmotionJoy,
+	"ワクワク": sentiment.EmotionJoy,
+	"わくわく": sentiment.EmotionJoy,
+	"笑":    sentiment.EmotionJoy,
+	"おめでと": sentiment.EmotionJoy,
+	"😊":    sentiment.EmotionJoy,
+	"😄":    sentiment.EmotionJoy,
+	"🥰":    sentiment.EmotionJoy,
+
+	// anger
+	"怒":    sentiment.EmotionAnger,
+	"腹立":   sentiment.EmotionAnger,
+	"むかつ":  sentiment.EmotionAnger,
+	"ムカつ":  sentiment.EmotionAnger,
+	"イライラ": sentiment.EmotionAnger,
+	"いらいら": sentiment.EmotionAnger,
+	"許せな":  sentiment.EmotionAnger,
+	"ふざけ":  sentiment.EmotionAnger,
+	"キレ":   sentiment.EmotionAnger,
+	"激おこ":  sentiment.EmotionAnger,
+	"😡":    sentiment.EmotionAnger,
+	"😠":    sentiment.EmotionAnger,
+
+	// sadness
+	"悲し":  sentiment.EmotionSadness,
+	"かなし": sentiment.EmotionSadness,
+	"寂し":  sentiment.EmotionSadness,
+	"さみし": sentiment.EmotionSadness,
+	"泣":   sentiment.EmotionSadness,
+	"残念":  sentiment.EmotionSadness,
+	"切な":  sentiment.EmotionSadness,
+	"せつな": sentiment.EmotionSadness,
+	"絶望":  sentiment.EmotionSadness,
+	"憂鬱":  sentiment.EmotionSadness,
+	"落ち込": sentiment.EmotionSadness,
+	"😢":   sentiment.EmotionSadness,
+	"😭":   sentiment.EmotionSadness,
+
+	// fear
+	"怖":  sentiment.EmotionFear,
+	"こわ": sentiment.EmotionFear,
+	"恐":  sentiment.EmotionFear,
+	"不安": sentiment.EmotionFear,
+	"心配": sentiment.EmotionFear,
+	"危な": sentiment.EmotionFear,
+	"ヤバ": sentiment.EmotionFear,
+	"震え": sentiment.EmotionFear,
+	"😨":  sentiment.EmotionFear,
+	"😱":  sentiment.EmotionFear,
+
+	// surprise
+	"驚":    sentiment.EmotionSurprise,
+	"びっくり": sentiment.EmotionSurprise,
+	"ビックリ": sentiment.EmotionSurprise,
+	"まさか":  sentiment.EmotionSurprise,
+	"意外":   sentiment.EmotionSurprise,
+	"信じられ": sentiment.EmotionSurprise,
+	"😲":    sentiment.EmotionSurprise,
+	"😮":    sentiment.EmotionSurprise,
+
+	// disgust
+	"気持ち悪": sentiment.EmotionDisgust,
+	"キモ":   sentiment.EmotionDisgust,
+	"きも":   sentiment.EmotionDisgust,
+	"嫌い":   sentiment.EmotionDisgust,
+	"きらい":  sentiment.EmotionDisgust,
+	"大嫌い":  sentiment.EmotionDisgust,
+	"うざ":   sentiment.EmotionDisgust,
+	"ウザ":   sentiment.EmotionDisgust,
+	"不快":   sentiment.EmotionDisgust,
+	"最低":   sentiment.EmotionDisgust,
+	"👎":    sentiment.EmotionDisgust,
+}
+
+// intensifiers multiply the polarity of the word which follows them.
+var intensifiers = map[string]float64{
+	"とても":    1.5,
+	"すごく":    1.5,
+	"凄く":     1.5,
+	"すごい":    1.3,
+	"めっちゃ":   1.5,
+	"めちゃくちゃ": 1.7,
+	"めちゃ":    1.5,
+	"超":      1.5,
+	"本当に":    1.4,
+	"ほんとに":   1.4,
+	"マジで":    1.4,
+	"まじで":    1.4,
+	"かなり":    1.3,
+	"非常に":    1.5,
+	"最も":     1.5,
+	"一番":     1.3,
+	"少し":     0.5,
+	"ちょっと":   0.6,
+	"やや":     0.5,
+	"あまり":    0.7,
+}
+
+// negations follow the word which they negate, like "楽しくない" and "好きじゃない".
+var negations = []string{
+	"ない",
+	"なかっ",
+	"なく",
+	"ません",
+	"ず",
+	"ぬ",
+}
+
+const (
+	// negationWindow is the number of characters after a word which are searched for negations.
+	negationWindow = 5
+
+	// negationFactor is multiplied to the polarity of a negated word.
+	// Negated words are weaker than their antonyms, e.g. "not good" is less negative than "bad".
+	negationFactor = -0.8
+)
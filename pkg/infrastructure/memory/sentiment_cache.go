@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
+)
+
+type lruSentimentCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  *list.List
+	elements map[string]*list.Element
+}
+
+type lruEntry struct {
+	key    string
+	output sentiment.DetectOutput
+}
+
+// NewLRUSentimentCache creates sentiment.Cache which keeps the latest used outputs up to capacity in memory.
+// It lives while the Lambda container is reused.
+func NewLRUSentimentCache(capacity int) sentiment.Cache {
+	return &lruSentimentCache{
+		capacity: capacity,
+		entries:  list.New(),
+		elements: map[string]*list.Element{},
+	}
+}
+
+func (c *lruSentimentCache) GetMulti(ctx context.Context, keys []string) (map[string]sentiment.DetectOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	res := map[string]sentiment.DetectOutput{}
+	for _, key := range keys {
+		if e, ok := c.elements[key]; ok {
+			c.entries.MoveToFront(e)
+			res[key] = e.Value.(*lruEntry).output
+		}
+	}
+	return res, nil
+}
+
+func (c *lruSentimentCache) PutMulti(ctx context.Context, outputs map[string]sentiment.DetectOutput) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, output := range outputs {
+		if e, ok := c.elements[key]; ok {
+			e.Value.(*lruEntry).output = output
+			c.entries.MoveToFront(e)
+			continue
+		}
+
+		c.elements[key] = c.entries.PushFront(&lruEntry{key, output})
+		for c.entries.Len() > c.capacity {
+			oldest := c.entries.Back()
+			c.entries.Remove(oldest)
+			delete(c.elements, oldest.Value.(*lruEntry).key)
+		}
+	}
+	return nil
+}
@@ -2,7 +2,9 @@ package twitter
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 
 	sdk "github.com/dghubble/go-twitter/twitter"
@@ -19,9 +21,9 @@ func NewTwitterOauth1Client(config *oauth1.Config) dtwitter.Client {
 	return &twitterOauth1Client{config}
 }
 
-func (c *twitterOauth1Client) Search(ctx context.Context, input *dtwitter.SearchInput) ([]dtwitter.Tweet, error) {
+func (c *twitterOauth1Client) Search(ctx context.Context, input *dtwitter.SearchInput) (*dtwitter.SearchOutput, error) {
 	client := c.makeTwitterClient(ctx, input.TwitterAccessToken, input.TwitterAccessTokenSecret)
-	search, _, err := client.Search.Tweets(&sdk.SearchTweetParams{
+	search, resp, err := client.Search.Tweets(&sdk.SearchTweetParams{
 		Query:           addExcludeRetweetOption(input.Query),
 		MaxID:           input.MaxID,
 		SinceID:         input.SinceID,
@@ -29,6 +31,12 @@ func (c *twitterOauth1Client) Search(ctx context.Context, input *dtwitter.Search
 		TweetMode:       "extended",
 		Count:           100,
 	})
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		return nil, newRateLimitError(resp.Header)
+	}
+	if isInvalidTokenError(err) {
+		return nil, fmt.Errorf("twitter error: %w: %s", dtwitter.ErrInvalidToken, err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("twitter error: %w", err)
 	}
@@ -55,7 +63,7 @@ func (c *twitterOauth1Client) Search(ctx context.Context, input *dtwitter.Search
 		})
 	}
 
-	return tweets, nil
+	return &dtwitter.SearchOutput{Tweets: tweets, RateLimit: parseRateLimit(resp.Header)}, nil
 }
 
 func makeEntities(tweet *sdk.Tweet) dtwitter.Entities {
@@ -117,6 +125,24 @@ func makeEntities(tweet *sdk.Tweet) dtwitter.Entities {
 	return entities
 }
 
+// invalidTokenErrorCode is the error code of v1.1 APIs which means the access token is invalid or expired.
+// API v2 returns it in the same format when its authentication fails.
+// 401 errors with other codes (e.g. 32) may be caused by the consumer key, so they do not mean revoked tokens.
+const invalidTokenErrorCode = 89
+
+func isInvalidTokenError(err error) bool {
+	var apiErr sdk.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, detail := range apiErr.Errors {
+		if detail.Code == invalidTokenErrorCode {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *twitterOauth1Client) makeTwitterClient(ctx context.Context, accessToken string, accessTokenSecret string) *sdk.Client {
 	token := oauth1.NewToken(accessToken, accessTokenSecret)
 	httpClient := c.config.Client(ctx, token)
@@ -124,6 +150,12 @@ func (c *twitterOauth1Client) makeTwitterClient(ctx context.Context, accessToken
 }
 
 func addExcludeRetweetOption(query string) string {
+	q, err := dtwitter.ParseQuery(query)
+	if err == nil {
+		return q.ExcludingRetweets()
+	}
+
+	// Searches which were saved before queries were validated may have invalid queries.
 	if !strings.Contains(query, "-filter:retweets") {
 		query += " -filter:retweets"
 	}
@@ -0,0 +1,28 @@
+package twitter
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	sdk "github.com/dghubble/go-twitter/twitter"
+)
+
+func Test_isInvalidTokenError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{sdk.APIError{Errors: []sdk.ErrorDetail{{Code: 89, Message: "Invalid or expired token."}}}, true},
+		{fmt.Errorf("wrapped: %w", sdk.APIError{Errors: []sdk.ErrorDetail{{Code: 89}}}), true},
+		{sdk.APIError{Errors: []sdk.ErrorDetail{{Code: 32, Message: "Could not authenticate you."}}}, false},
+		{errors.New("network error"), false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := isInvalidTokenError(tt.err); got != tt.want {
+			t.Errorf("isInvalidTokenError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package twitter
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	dtwitter "github.com/hareku/emosearch-api/pkg/domain/twitter"
+)
+
+// rateLimitWindow is the rate limit window of the search APIs, which is assumed when the response has no reset header.
+const rateLimitWindow = 15 * time.Minute
+
+// newRateLimitError creates RateLimitError from x-rate-limit-* headers of the response.
+func newRateLimitError(header http.Header) *dtwitter.RateLimitError {
+	rl, _ := rateLimitFromHeader(header)
+	return rl.Err()
+}
+
+// parseRateLimit parses x-rate-limit-* headers of the successful response,
+// and returns nil when the response has no remaining header.
+func parseRateLimit(header http.Header) *dtwitter.RateLimit {
+	rl, ok := rateLimitFromHeader(header)
+	if !ok {
+		return nil
+	}
+	return rl
+}
+
+// rateLimitFromHeader parses x-rate-limit-* headers, and reports whether the remaining header was found.
+func rateLimitFromHeader(header http.Header) (*dtwitter.RateLimit, bool) {
+	rl := &dtwitter.RateLimit{Reset: time.Now().Add(rateLimitWindow)}
+	if v, err := strconv.Atoi(header.Get("x-rate-limit-limit")); err == nil {
+		rl.Limit = v
+	}
+	if v, err := strconv.ParseInt(header.Get("x-rate-limit-reset"), 10, 64); err == nil {
+		rl.Reset = time.Unix(v, 0)
+	}
+	v, err := strconv.Atoi(header.Get("x-rate-limit-remaining"))
+	if err != nil {
+		return rl, false
+	}
+	rl.Remaining = v
+	return rl, true
+}
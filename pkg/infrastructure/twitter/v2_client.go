@@ -0,0 +1,370 @@
+package twitter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dghubble/oauth1"
+	dtwitter "github.com/hareku/emosearch-api/pkg/domain/twitter"
+)
+
+// DefaultV2BaseURL is the base URL of Twitter API v2.
+const DefaultV2BaseURL = "https://api.twitter.com"
+
+const (
+	v2SearchPath = "/2/tweets/search/recent"
+	// v2MaxResults is the maximum number of tweets of a page of recent search.
+	v2MaxResults = 100
+	// v2MaxPages limits pages which are fetched by a Search call.
+	v2MaxPages = 5
+	// v2SearchWindow is the period which recent search covers.
+	v2SearchWindow = dtwitter.SearchWindow
+)
+
+type twitterV2Client struct {
+	config  *oauth1.Config
+	baseURL string
+}
+
+// NewTwitterV2Client creates Client of domain Twitter which uses recent search of Twitter API v2.
+// baseURL is the base URL of the API, DefaultV2BaseURL is used if it is empty.
+func NewTwitterV2Client(config *oauth1.Config, baseURL string) dtwitter.Client {
+	if baseURL == "" {
+		baseURL = DefaultV2BaseURL
+	}
+	return &twitterV2Client{config, strings.TrimSuffix(baseURL, "/")}
+}
+
+// Search searches tweets which are newer than SinceID and older than MaxID in descending order.
+// Unlike the v1.1 standard search, MaxID itself is not included.
+// It follows next_token until it collects a page of tweets, or the rate limit is exhausted.
+func (c *twitterV2Client) Search(ctx context.Context, input *dtwitter.SearchInput) (*dtwitter.SearchOutput, error) {
+	query, err := v2ExcludeRetweetQuery(input.Query)
+	if err != nil {
+		return nil, fmt.Errorf("twitter error: %w", err)
+	}
+
+	// Recent search rejects IDs which are older than its window.
+	windowStart := time.Now().Add(-v2SearchWindow + time.Minute)
+	if input.MaxID != 0 && dtwitter.TweetIDTime(input.MaxID).Before(windowStart) {
+		return &dtwitter.SearchOutput{Tweets: []dtwitter.Tweet{}}, nil
+	}
+
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("max_results", strconv.Itoa(v2MaxResults))
+	params.Set("tweet.fields", "created_at,author_id,entities,attachments,referenced_tweets")
+	params.Set("expansions", "author_id,attachments.media_keys,referenced_tweets.id")
+	params.Set("user.fields", "name,username,profile_image_url")
+	params.Set("media.fields", "type,url,preview_image_url,duration_ms,width,height,variants")
+	if input.SinceID != 0 && !dtwitter.TweetIDTime(input.SinceID).Before(windowStart) {
+		params.Set("since_id", strconv.FormatInt(input.SinceID, 10))
+	}
+	if input.MaxID != 0 {
+		params.Set("until_id", strconv.FormatInt(input.MaxID, 10))
+	}
+
+	httpClient := c.config.Client(ctx, oauth1.NewToken(input.TwitterAccessToken, input.TwitterAccessTokenSecret))
+	output := &dtwitter.SearchOutput{Tweets: []dtwitter.Tweet{}}
+
+	for page := 0; page < v2MaxPages && len(output.Tweets) < v2MaxResults; page++ {
+		res, rateLimit, err := c.search(ctx, httpClient, params)
+		if err != nil {
+			return nil, err
+		}
+		output.RateLimit = rateLimit
+
+		pageTweets, err := res.tweets()
+		if err != nil {
+			return nil, err
+		}
+		output.Tweets = append(output.Tweets, pageTweets...)
+
+		if res.Meta.NextToken == "" || rateLimit.Exhausted() {
+			break
+		}
+		params.Set("next_token", res.Meta.NextToken)
+	}
+
+	return output, nil
+}
+
+func (c *twitterV2Client) search(ctx context.Context, httpClient *http.Client, params url.Values) (*v2SearchResponse, *dtwitter.RateLimit, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+v2SearchPath+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create twitter request: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("twitter error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read twitter response: %w", err)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, nil, newRateLimitError(resp.Header)
+	}
+	if resp.StatusCode == http.StatusUnauthorized && isV2InvalidTokenResponse(body) {
+		return nil, nil, fmt.Errorf("twitter error: %w: %s", dtwitter.ErrInvalidToken, strings.TrimSpace(string(body)))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("twitter error: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	res := &v2SearchResponse{}
+	err = json.Unmarshal(body, res)
+	if err != nil {
+		return nil, nil, fmt.Errorf("twitter response json unmarshal error: %w", err)
+	}
+	return res, parseRateLimit(resp.Header), nil
+}
+
+// v2ErrorResponse is the body of errors which are returned by the authentication of requests.
+// It has the codes of v1.1 APIs, unlike errors of requests in the format of API v2.
+type v2ErrorResponse struct {
+	Errors []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// isV2InvalidTokenResponse reports whether the body of the 401 response means the access token is invalid or expired.
+// Other 401 errors (e.g. a wrong consumer key, or a suspended app) are not the errors of the user's token.
+func isV2InvalidTokenResponse(body []byte) bool {
+	res := &v2ErrorResponse{}
+	if err := json.Unmarshal(body, res); err != nil {
+		return false
+	}
+	for _, e := range res.Errors {
+		if e.Code == invalidTokenErrorCode {
+			return true
+		}
+	}
+	return false
+}
+
+type v2SearchResponse struct {
+	Data     []v2Tweet `json:"data"`
+	Includes struct {
+		Users []v2User  `json:"users"`
+		Media []v2Media `json:"media"`
+	} `json:"includes"`
+	Meta struct {
+		NextToken string `json:"next_token"`
+	} `json:"meta"`
+}
+
+type v2Tweet struct {
+	ID        string `json:"id"`
+	Text      string `json:"text"`
+	AuthorID  string `json:"author_id"`
+	CreatedAt string `json:"created_at"`
+	Entities  struct {
+		Hashtags []struct {
+			Start int    `json:"start"`
+			End   int    `json:"end"`
+			Tag   string `json:"tag"`
+		} `json:"hashtags"`
+		Mentions []struct {
+			Start    int    `json:"start"`
+			End      int    `json:"end"`
+			Username string `json:"username"`
+		} `json:"mentions"`
+		URLs []struct {
+			Start       int    `json:"start"`
+			End         int    `json:"end"`
+			URL         string `json:"url"`
+			ExpandedURL string `json:"expanded_url"`
+			DisplayURL  string `json:"display_url"`
+			MediaKey    string `json:"media_key"`
+		} `json:"urls"`
+	} `json:"entities"`
+	ReferencedTweets []struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+	} `json:"referenced_tweets"`
+}
+
+type v2User struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Username        string `json:"username"`
+	ProfileImageURL string `json:"profile_image_url"`
+}
+
+type v2Media struct {
+	MediaKey        string `json:"media_key"`
+	Type            string `json:"type"`
+	URL             string `json:"url"`
+	PreviewImageURL string `json:"preview_image_url"`
+	DurationMillis  int    `json:"duration_ms"`
+	Width           int    `json:"width"`
+	Height          int    `json:"height"`
+	Variants        []struct {
+		BitRate     int    `json:"bit_rate"`
+		ContentType string `json:"content_type"`
+		URL         string `json:"url"`
+	} `json:"variants"`
+}
+
+// tweets maps tweets of the page into domain tweets with their expanded authors and media.
+func (res *v2SearchResponse) tweets() ([]dtwitter.Tweet, error) {
+	users := map[string]*dtwitter.User{}
+	for _, u := range res.Includes.Users {
+		id, err := strconv.ParseInt(u.ID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("user id parse error: %w", err)
+		}
+		users[u.ID] = &dtwitter.User{
+			ID:              id,
+			Name:            u.Name,
+			ScreenName:      u.Username,
+			ProfileImageURL: u.ProfileImageURL,
+		}
+	}
+
+	media := map[string]*v2Media{}
+	for i := range res.Includes.Media {
+		media[res.Includes.Media[i].MediaKey] = &res.Includes.Media[i]
+	}
+
+	tweets := []dtwitter.Tweet{}
+	for i := range res.Data {
+		tweet := &res.Data[i]
+		if tweet.isRetweet() {
+			continue
+		}
+
+		id, err := strconv.ParseInt(tweet.ID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("tweet id parse error: %w", err)
+		}
+		user, ok := users[tweet.AuthorID]
+		if !ok {
+			return nil, fmt.Errorf("author (id: %s) of tweet (id: %s) was not expanded", tweet.AuthorID, tweet.ID)
+		}
+		createdAt, err := time.Parse(time.RFC3339, tweet.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("tweet created_at parse error: %w", err)
+		}
+
+		tweets = append(tweets, dtwitter.Tweet{
+			TweetID:   id,
+			AuthorID:  user.ID,
+			User:      user,
+			Entities:  makeV2Entities(tweet, media),
+			Text:      tweet.Text,
+			CreatedAt: createdAt,
+		})
+	}
+
+	return tweets, nil
+}
+
+// isRetweet reports whether the tweet is a retweet, they are excluded by queries but skipped just in case.
+func (t *v2Tweet) isRetweet() bool {
+	for _, ref := range t.ReferencedTweets {
+		if ref.Type == "retweeted" {
+			return true
+		}
+	}
+	return false
+}
+
+// makeV2Entities maps entities of the tweet. URLs of media are mapped into media like v1.1 entities.
+func makeV2Entities(tweet *v2Tweet, media map[string]*v2Media) dtwitter.Entities {
+	entities := dtwitter.Entities{}
+
+	for _, hashtag := range tweet.Entities.Hashtags {
+		entities.HashTags = append(entities.HashTags, dtwitter.HashTag{
+			Start: hashtag.Start,
+			End:   hashtag.End,
+			Tag:   hashtag.Tag,
+		})
+	}
+
+	for _, mention := range tweet.Entities.Mentions {
+		entities.Mentions = append(entities.Mentions, dtwitter.Mention{
+			Start: mention.Start,
+			End:   mention.End,
+			Tag:   mention.Username,
+		})
+	}
+
+	for _, u := range tweet.Entities.URLs {
+		medium, ok := media[u.MediaKey]
+		if u.MediaKey == "" || !ok {
+			entities.URLs = append(entities.URLs, dtwitter.URL{
+				Start:       u.Start,
+				End:         u.End,
+				URL:         u.URL,
+				DisplayURL:  u.DisplayURL,
+				ExpandedURL: u.ExpandedURL,
+			})
+			continue
+		}
+
+		m := dtwitter.Medium{
+			Start:    u.Start,
+			End:      u.End,
+			URL:      u.URL,
+			MediaURL: medium.URL,
+			Type:     medium.Type,
+		}
+		if m.MediaURL == "" {
+			m.MediaURL = medium.PreviewImageURL
+		}
+		if len(medium.Variants) > 0 {
+			m.VideoInfo = &dtwitter.VideoInfo{
+				AspectRatio:    aspectRatio(medium.Width, medium.Height),
+				DurationMillis: medium.DurationMillis,
+			}
+			for _, v := range medium.Variants {
+				m.VideoInfo.Variants = append(m.VideoInfo.Variants, dtwitter.VideoVariant{
+					ContentType: v.ContentType,
+					Bitrate:     v.BitRate,
+					URL:         v.URL,
+				})
+			}
+		}
+		entities.Media = append(entities.Media, m)
+	}
+
+	return entities
+}
+
+// aspectRatio reduces the size into the aspect ratio like video_info of v1.1 (e.g. [16, 9]).
+func aspectRatio(width, height int) [2]int {
+	a, b := width, height
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a == 0 {
+		return [2]int{}
+	}
+	return [2]int{width / a, height / a}
+}
+
+func v2ExcludeRetweetQuery(query string) (string, error) {
+	q, err := dtwitter.ParseQuery(query)
+	if err == nil {
+		return q.V2ExcludingRetweets()
+	}
+
+	// Searches which were saved before queries were validated may have invalid queries.
+	if !strings.Contains(query, "-is:retweet") {
+		query += " -is:retweet"
+	}
+	return query, nil
+}
@@ -0,0 +1,244 @@
+package twitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dghubble/oauth1"
+	dtwitter "github.com/hareku/emosearch-api/pkg/domain/twitter"
+)
+
+func Test_twitterV2Client_Search(t *testing.T) {
+	sinceID := dtwitter.MinTweetIDAt(time.Now().Add(-time.Hour))
+	maxID := dtwitter.MinTweetIDAt(time.Now())
+
+	pages := map[string]string{
+		"": `{
+			"data": [
+				{
+					"id": "1002", "text": "楽しい #cat @jack https://t.co/a https://t.co/m", "author_id": "10",
+					"created_at": "2021-01-02T03:04:05.000Z",
+					"entities": {
+						"hashtags": [{"start": 4, "end": 8, "tag": "cat"}],
+						"mentions": [{"start": 9, "end": 14, "username": "jack"}],
+						"urls": [
+							{"start": 15, "end": 29, "url": "https://t.co/a", "expanded_url": "https://example.com", "display_url": "example.com"},
+							{"start": 30, "end": 44, "url": "https://t.co/m", "media_key": "7_1"}
+						]
+					}
+				},
+				{
+					"id": "1001", "text": "RT @jack: cat", "author_id": "10", "created_at": "2021-01-02T03:04:00.000Z",
+					"referenced_tweets": [{"type": "retweeted", "id": "900"}]
+				}
+			],
+			"includes": {
+				"users": [{"id": "10", "name": "Jack", "username": "jack", "profile_image_url": "https://example.com/jack.png"}],
+				"media": [{
+					"media_key": "7_1", "type": "video", "preview_image_url": "https://example.com/preview.jpg",
+					"duration_ms": 1500, "width": 1280, "height": 720,
+					"variants": [{"bit_rate": 832000, "content_type": "video/mp4", "url": "https://example.com/video.mp4"}]
+				}]
+			},
+			"meta": {"next_token": "page2"}
+		}`,
+		"page2": `{
+			"data": [{"id": "1000", "text": "cat", "author_id": "11", "created_at": "2021-01-02T03:03:00.000Z"}],
+			"includes": {"users": [{"id": "11", "name": "Ann", "username": "ann"}]},
+			"meta": {}
+		}`,
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/2/tweets/search/recent" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") == "" {
+			t.Error("request is not signed")
+		}
+
+		params := r.URL.Query()
+		want := map[string]string{
+			"query":       "cat lang:ja -is:retweet",
+			"max_results": "100",
+			"since_id":    strconv.FormatInt(sinceID, 10),
+			"until_id":    strconv.FormatInt(maxID, 10),
+			"expansions":  "author_id,attachments.media_keys,referenced_tweets.id",
+		}
+		for key, value := range want {
+			if got := params.Get(key); got != value {
+				t.Errorf("param %s = %q, want %q", key, got, value)
+			}
+		}
+
+		page, ok := pages[params.Get("next_token")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, page)
+	}))
+	defer server.Close()
+
+	c := NewTwitterV2Client(oauth1.NewConfig("key", "secret"), server.URL)
+	output, err := c.Search(context.Background(), &dtwitter.SearchInput{
+		Query:                    "cat lang:ja",
+		TwitterAccessToken:       "token",
+		TwitterAccessTokenSecret: "token-secret",
+		SinceID:                  sinceID,
+		MaxID:                    maxID,
+	})
+	if err != nil {
+		t.Fatalf("Search returned error: %s", err)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+
+	jack := &dtwitter.User{ID: 10, Name: "Jack", ScreenName: "jack", ProfileImageURL: "https://example.com/jack.png"}
+	want := []dtwitter.Tweet{
+		{
+			TweetID:  1002,
+			AuthorID: 10,
+			User:     jack,
+			Text:     "楽しい #cat @jack https://t.co/a https://t.co/m",
+			Entities: dtwitter.Entities{
+				HashTags: []dtwitter.HashTag{{Start: 4, End: 8, Tag: "cat"}},
+				Mentions: []dtwitter.Mention{{Start: 9, End: 14, Tag: "jack"}},
+				URLs:     []dtwitter.URL{{Start: 15, End: 29, URL: "https://t.co/a", ExpandedURL: "https://example.com", DisplayURL: "example.com"}},
+				Media: []dtwitter.Medium{{
+					Start:    30,
+					End:      44,
+					URL:      "https://t.co/m",
+					MediaURL: "https://example.com/preview.jpg",
+					Type:     "video",
+					VideoInfo: &dtwitter.VideoInfo{
+						AspectRatio:    [2]int{16, 9},
+						DurationMillis: 1500,
+						Variants:       []dtwitter.VideoVariant{{ContentType: "video/mp4", Bitrate: 832000, URL: "https://example.com/video.mp4"}},
+					},
+				}},
+			},
+			CreatedAt: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{
+			TweetID:   1000,
+			AuthorID:  11,
+			User:      &dtwitter.User{ID: 11, Name: "Ann", ScreenName: "ann"},
+			Text:      "cat",
+			CreatedAt: time.Date(2021, 1, 2, 3, 3, 0, 0, time.UTC),
+		},
+	}
+	if !reflect.DeepEqual(output.Tweets, want) {
+		t.Errorf("Search returned %+v, want %+v", output.Tweets, want)
+	}
+	if output.RateLimit != nil {
+		t.Errorf("Search returned rate limit %+v without rate limit headers", output.RateLimit)
+	}
+}
+
+func Test_twitterV2Client_Search_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"title": "Invalid Request"}`)
+	}))
+	defer server.Close()
+
+	c := NewTwitterV2Client(oauth1.NewConfig("key", "secret"), server.URL)
+	_, err := c.Search(context.Background(), &dtwitter.SearchInput{Query: "cat"})
+	if err == nil {
+		t.Error("Search returned no error")
+	}
+}
+
+func Test_twitterV2Client_Search_RateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-rate-limit-limit", "180")
+		w.Header().Set("x-rate-limit-remaining", "0")
+		w.Header().Set("x-rate-limit-reset", "1609556645")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	c := NewTwitterV2Client(oauth1.NewConfig("key", "secret"), server.URL)
+	_, err := c.Search(context.Background(), &dtwitter.SearchInput{Query: "cat"})
+
+	var rateLimitErr *dtwitter.RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("Search returned %v, want RateLimitError", err)
+	}
+	want := &dtwitter.RateLimitError{Limit: 180, Remaining: 0, Reset: time.Unix(1609556645, 0)}
+	if !reflect.DeepEqual(rateLimitErr, want) {
+		t.Errorf("Search returned %+v, want %+v", rateLimitErr, want)
+	}
+}
+
+func Test_twitterV2Client_Search_RateLimitExhausted(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("x-rate-limit-limit", "180")
+		w.Header().Set("x-rate-limit-remaining", "0")
+		w.Header().Set("x-rate-limit-reset", "1609556645")
+		fmt.Fprint(w, `{
+			"data": [{"id": "1000", "text": "cat", "author_id": "11", "created_at": "2021-01-02T03:03:00.000Z"}],
+			"includes": {"users": [{"id": "11", "name": "Ann", "username": "ann"}]},
+			"meta": {"next_token": "page2"}
+		}`)
+	}))
+	defer server.Close()
+
+	c := NewTwitterV2Client(oauth1.NewConfig("key", "secret"), server.URL)
+	output, err := c.Search(context.Background(), &dtwitter.SearchInput{Query: "cat"})
+	if err != nil {
+		t.Fatalf("Search returned error: %s", err)
+	}
+
+	// The next page is not requested, because it fails until the reset.
+	if requests != 1 || len(output.Tweets) != 1 {
+		t.Errorf("Search sent %d requests and returned %d tweets, want 1 request and 1 tweet", requests, len(output.Tweets))
+	}
+	want := &dtwitter.RateLimit{Limit: 180, Remaining: 0, Reset: time.Unix(1609556645, 0)}
+	if !reflect.DeepEqual(output.RateLimit, want) || !output.RateLimit.Exhausted() {
+		t.Errorf("Search returned rate limit %+v, want exhausted %+v", output.RateLimit, want)
+	}
+}
+
+func Test_twitterV2Client_Search_Unauthorized(t *testing.T) {
+	tests := []struct {
+		name             string
+		body             string
+		wantInvalidToken bool
+	}{
+		{"invalid token", `{"errors": [{"code": 89, "message": "Invalid or expired token."}]}`, true},
+		{"invalid consumer key", `{"errors": [{"code": 32, "message": "Could not authenticate you."}]}`, false},
+		{"problem of API v2", `{"title": "Unauthorized", "type": "about:blank", "status": 401, "detail": "Unauthorized"}`, false},
+		{"not json", `Unauthorized`, false},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, tt.body)
+		}))
+
+		c := NewTwitterV2Client(oauth1.NewConfig("key", "secret"), server.URL)
+		_, err := c.Search(context.Background(), &dtwitter.SearchInput{Query: "cat"})
+		if err == nil {
+			t.Errorf("%s: Search returned no error", tt.name)
+		}
+		if got := errors.Is(err, dtwitter.ErrInvalidToken); got != tt.wantInvalidToken {
+			t.Errorf("%s: Search returned %v, want ErrInvalidToken: %t", tt.name, err, tt.wantInvalidToken)
+		}
+		server.Close()
+	}
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/aquasecurity/lmdrouter"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+	"github.com/hareku/emosearch-api/pkg/domain/repository"
+	"github.com/hareku/emosearch-api/pkg/domain/validator"
+	"github.com/hareku/emosearch-api/pkg/usecase"
+)
+
+func (h *handler) registerAdminRoutes() {
+	h.router.Route("PUT", "/admin/users/:id/quota", h.updateUserQuota())
+	h.router.Route("DELETE", "/admin/users/:id/quota", h.resetUserQuota())
+	h.router.Route("GET", "/admin/filter-rules", h.fetchGlobalFilterRules())
+	h.router.Route("PUT", "/admin/filter-rules", h.updateGlobalFilterRules())
+}
+
+type updateUserQuotaInput struct {
+	UserID             model.UserID `lambda:"path.id"`
+	MaxActiveSearches  int          `json:"MaxActiveSearches"`
+	MaxTweetsPerMonth  int64        `json:"MaxTweetsPerMonth"`
+	MinIntervalMinutes int          `json:"MinIntervalMinutes"`
+}
+
+func (h *handler) updateUserQuota() lmdrouter.Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (
+		res events.APIGatewayProxyResponse,
+		err error,
+	) {
+		var input updateUserQuotaInput
+		err = lmdrouter.UnmarshalRequest(req, true, &input)
+		if err != nil {
+			return lmdrouter.HandleError(err)
+		}
+
+		u := h.registry.NewUserUsecase()
+		user, err := u.UpdateUserQuota(ctx, &usecase.UserUsecaseUpdateQuotaInput{
+			UserID:             input.UserID,
+			MaxActiveSearches:  input.MaxActiveSearches,
+			MaxTweetsPerMonth:  input.MaxTweetsPerMonth,
+			MinIntervalMinutes: input.MinIntervalMinutes,
+		})
+		var errv validator.ErrValidation
+		if errors.As(err, &errv) {
+			return h.handleValidationErrors(errv)
+		}
+
+		return h.handleUserQuotaResponse(user, err)
+	}
+}
+
+type resetUserQuotaInput struct {
+	UserID model.UserID `lambda:"path.id"`
+}
+
+func (h *handler) resetUserQuota() lmdrouter.Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (
+		res events.APIGatewayProxyResponse,
+		err error,
+	) {
+		var input resetUserQuotaInput
+		err = lmdrouter.UnmarshalRequest(req, false, &input)
+		if err != nil {
+			return lmdrouter.HandleError(err)
+		}
+
+		u := h.registry.NewUserUsecase()
+		user, err := u.ResetUserQuota(ctx, input.UserID)
+
+		return h.handleUserQuotaResponse(user, err)
+	}
+}
+
+type userQuotaRes struct {
+	UserID model.UserID
+	Quota  model.Quota
+}
+
+func (h *handler) handleUserQuotaResponse(user *model.User, err error) (events.APIGatewayProxyResponse, error) {
+	if errors.Is(err, usecase.ErrNotAdmin) {
+		return lmdrouter.HandleError(lmdrouter.HTTPError{
+			Code:    http.StatusForbidden,
+			Message: "admin permission is required",
+		})
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return lmdrouter.HandleError(lmdrouter.HTTPError{
+			Code:    http.StatusNotFound,
+			Message: "specified user was not found",
+		})
+	}
+	if err != nil {
+		return lmdrouter.HandleError(err)
+	}
+
+	// The response does not include twitter access tokens of the user.
+	return lmdrouter.MarshalResponse(http.StatusOK, nil, userQuotaRes{
+		UserID: user.UserID,
+		Quota:  user.CurrentQuota(),
+	})
+}
+
+func (h *handler) fetchGlobalFilterRules() lmdrouter.Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (
+		res events.APIGatewayProxyResponse,
+		err error,
+	) {
+		u := h.registry.NewFilterRulesUsecase()
+		rules, err := u.GetGlobalFilterRules(ctx)
+
+		return h.handleFilterRulesResponse(rules, err)
+	}
+}
+
+func (h *handler) updateGlobalFilterRules() lmdrouter.Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (
+		res events.APIGatewayProxyResponse,
+		err error,
+	) {
+		var input usecase.FilterRulesInput
+		err = lmdrouter.UnmarshalRequest(req, true, &input)
+		if err != nil {
+			return lmdrouter.HandleError(err)
+		}
+
+		u := h.registry.NewFilterRulesUsecase()
+		rules, err := u.UpdateGlobalFilterRules(ctx, &input)
+		var errv validator.ErrValidation
+		if errors.As(err, &errv) {
+			return h.handleValidationErrors(errv)
+		}
+
+		return h.handleFilterRulesResponse(rules, err)
+	}
+}
+
+func (h *handler) handleFilterRulesResponse(rules *model.FilterRules, err error) (events.APIGatewayProxyResponse, error) {
+	if errors.Is(err, usecase.ErrNotAdmin) {
+		return lmdrouter.HandleError(lmdrouter.HTTPError{
+			Code:    http.StatusForbidden,
+			Message: "admin permission is required",
+		})
+	}
+	if err != nil {
+		return lmdrouter.HandleError(err)
+	}
+
+	return lmdrouter.MarshalResponse(http.StatusOK, nil, rules)
+}
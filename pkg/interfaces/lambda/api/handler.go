@@ -6,8 +6,10 @@ import (
 	"github.com/aquasecurity/lmdrouter"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/hareku/emosearch-api/pkg/domain/model"
 	"github.com/hareku/emosearch-api/pkg/domain/validator"
 	"github.com/hareku/emosearch-api/pkg/registry"
+	"github.com/hareku/emosearch-api/pkg/usecase"
 )
 
 type handler struct {
@@ -41,9 +43,34 @@ func (h *handler) registerRoutes() {
 	h.registerSearchRoutes()
 	h.registerUserRoutes()
 	h.registerTweetRoutes()
+	h.registerAdminRoutes()
 }
 
 func (h *handler) handleValidationErrors(verr validator.ErrValidation) (events.APIGatewayProxyResponse, error) {
 	body := verr.ToMap()
 	return lmdrouter.MarshalResponse(http.StatusUnprocessableEntity, nil, body)
 }
+
+type quotaExceededRes struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Quota   model.QuotaName `json:"quota"`
+	Limit   int64           `json:"limit"`
+	Current int64           `json:"current"`
+}
+
+// handleQuotaExceededError responds 429 if the monthly tweets quota is exceeded, otherwise 403.
+func (h *handler) handleQuotaExceededError(qerr *usecase.QuotaExceededError) (events.APIGatewayProxyResponse, error) {
+	code := http.StatusForbidden
+	if qerr.Quota == model.QuotaMaxTweetsPerMonth {
+		code = http.StatusTooManyRequests
+	}
+
+	return lmdrouter.MarshalResponse(code, nil, quotaExceededRes{
+		Code:    code,
+		Message: qerr.Error(),
+		Quota:   qerr.Quota,
+		Limit:   qerr.Limit,
+		Current: qerr.Current,
+	})
+}
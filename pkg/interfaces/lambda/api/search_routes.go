@@ -8,6 +8,7 @@ import (
 	"github.com/aquasecurity/lmdrouter"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/hareku/emosearch-api/pkg/domain/model"
+	"github.com/hareku/emosearch-api/pkg/domain/repository"
 	"github.com/hareku/emosearch-api/pkg/domain/validator"
 	"github.com/hareku/emosearch-api/pkg/usecase"
 )
@@ -15,7 +16,13 @@ import (
 func (h *handler) registerSearchRoutes() {
 	h.router.Route("GET", "/searches", h.fetchSearches())
 	h.router.Route("GET", "/searches/:id", h.fetchSearch())
+	h.router.Route("PATCH", "/searches/:id", h.updateSearch())
 	h.router.Route("DELETE", "/searches/:id", h.deleteSearch())
+	h.router.Route("GET", "/searches/:id/runs", h.fetchSearchRuns())
+	h.router.Route("GET", "/searches/:id/rescorings", h.fetchSearchRescorings())
+	h.router.Route("POST", "/searches/:id/pause", h.changeSearchStatus(model.SearchStatusPaused))
+	h.router.Route("POST", "/searches/:id/resume", h.changeSearchStatus(model.SearchStatusActive))
+	h.router.Route("POST", "/searches/:id/archive", h.changeSearchStatus(model.SearchStatusArchived))
 	h.router.Route("POST", "/searches", h.createSearch())
 }
 
@@ -38,6 +45,11 @@ type fetchSearchInput struct {
 	SearchID model.SearchID `lambda:"path.id"`
 }
 
+type fetchSearchRes struct {
+	*model.Search
+	Stats *model.SearchStats
+}
+
 func (h *handler) fetchSearch() lmdrouter.Handler {
 	return func(ctx context.Context, req events.APIGatewayProxyRequest) (
 		res events.APIGatewayProxyResponse,
@@ -61,7 +73,96 @@ func (h *handler) fetchSearch() lmdrouter.Handler {
 			})
 		}
 
-		return lmdrouter.MarshalResponse(http.StatusOK, nil, search)
+		stats, err := u.GetSearchStats(ctx, search)
+		if err != nil {
+			return lmdrouter.HandleError(err)
+		}
+
+		return lmdrouter.MarshalResponse(http.StatusOK, nil, fetchSearchRes{search, stats})
+	}
+}
+
+type fetchSearchRunsInput struct {
+	SearchID model.SearchID `lambda:"path.id"`
+	Limit    int64          `lambda:"query.limit"`
+}
+
+type fetchSearchRunsRes struct {
+	Runs []*model.CollectionRun
+}
+
+const (
+	defaultSearchRunsLimit = 20
+	maxSearchRunsLimit     = 100
+)
+
+func (h *handler) fetchSearchRuns() lmdrouter.Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (
+		res events.APIGatewayProxyResponse,
+		err error,
+	) {
+		var input fetchSearchRunsInput
+		err = lmdrouter.UnmarshalRequest(req, false, &input)
+		if err != nil {
+			return lmdrouter.HandleError(err)
+		}
+		if input.Limit <= 0 {
+			input.Limit = defaultSearchRunsLimit
+		}
+		if input.Limit > maxSearchRunsLimit {
+			input.Limit = maxSearchRunsLimit
+		}
+
+		u := h.registry.NewSearchUsecase()
+		runs, err := u.ListUserSearchRuns(ctx, input.SearchID, input.Limit)
+		if errors.Is(err, repository.ErrNotFound) {
+			return lmdrouter.HandleError(lmdrouter.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "specified search was not found",
+			})
+		}
+		if err != nil {
+			return lmdrouter.HandleError(err)
+		}
+
+		return lmdrouter.MarshalResponse(http.StatusOK, nil, fetchSearchRunsRes{runs})
+	}
+}
+
+type fetchSearchRescoringsRes struct {
+	Rescorings []*model.TweetRescoring
+}
+
+func (h *handler) fetchSearchRescorings() lmdrouter.Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (
+		res events.APIGatewayProxyResponse,
+		err error,
+	) {
+		var input fetchSearchRunsInput
+		err = lmdrouter.UnmarshalRequest(req, false, &input)
+		if err != nil {
+			return lmdrouter.HandleError(err)
+		}
+		if input.Limit <= 0 {
+			input.Limit = defaultSearchRunsLimit
+		}
+		if input.Limit > maxSearchRunsLimit {
+			input.Limit = maxSearchRunsLimit
+		}
+
+		u := h.registry.NewSearchUsecase()
+		rescorings, err := u.ListUserSearchRescorings(ctx, input.SearchID, input.Limit)
+		if errors.Is(err, repository.ErrNotFound) {
+			return lmdrouter.HandleError(lmdrouter.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "specified search was not found",
+			})
+		}
+		if err != nil {
+			return lmdrouter.HandleError(err)
+		}
+
+		return lmdrouter.MarshalResponse(http.StatusOK, nil, fetchSearchRescoringsRes{rescorings})
 	}
 }
 
@@ -81,17 +182,29 @@ func (h *handler) deleteSearch() lmdrouter.Handler {
 		}
 
 		u := h.registry.NewSearchUsecase()
-		err = u.DeleteUserSearch(ctx, input.SearchID)
+		search, err := u.DeleteUserSearch(ctx, input.SearchID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return lmdrouter.HandleError(lmdrouter.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "specified search was not found",
+			})
+		}
 		if err != nil {
 			return lmdrouter.HandleError(err)
 		}
 
-		return lmdrouter.MarshalResponse(http.StatusNoContent, nil, nil)
+		// Tweets of the search are deleted asynchronously, and the progress is reported by the search.
+		return lmdrouter.MarshalResponse(http.StatusAccepted, nil, search)
 	}
 }
 
 type createSearchInput struct {
-	Query string `json:"Query"`
+	Title             string                    `json:"Title"`
+	Query             string                    `json:"Query"`
+	IntervalMinutes   int                       `json:"IntervalMinutes"`
+	AdaptiveInterval  bool                      `json:"AdaptiveInterval"`
+	SentimentDetector string                    `json:"SentimentDetector"`
+	FilterRules       *usecase.FilterRulesInput `json:"FilterRules"`
 }
 
 func (h *handler) createSearch() lmdrouter.Handler {
@@ -107,12 +220,21 @@ func (h *handler) createSearch() lmdrouter.Handler {
 
 		u := h.registry.NewSearchUsecase()
 		search, err := u.Create(ctx, &usecase.SearchUsecaseCreateInput{
-			Query: input.Query,
+			Title:             input.Title,
+			Query:             input.Query,
+			IntervalMinutes:   input.IntervalMinutes,
+			AdaptiveInterval:  input.AdaptiveInterval,
+			SentimentDetector: input.SentimentDetector,
+			FilterRules:       input.FilterRules,
 		})
 		var errv validator.ErrValidation
 		if errors.As(err, &errv) {
 			return h.handleValidationErrors(errv)
 		}
+		var qerr *usecase.QuotaExceededError
+		if errors.As(err, &qerr) {
+			return h.handleQuotaExceededError(qerr)
+		}
 		if err != nil {
 			return lmdrouter.HandleError(err)
 		}
@@ -120,3 +242,115 @@ func (h *handler) createSearch() lmdrouter.Handler {
 		return lmdrouter.MarshalResponse(http.StatusCreated, nil, search)
 	}
 }
+
+type updateSearchInput struct {
+	SearchID          model.SearchID            `lambda:"path.id"`
+	Title             *string                   `json:"Title"`
+	Query             *string                   `json:"Query"`
+	IntervalMinutes   *int                      `json:"IntervalMinutes"`
+	AdaptiveInterval  *bool                     `json:"AdaptiveInterval"`
+	SentimentDetector *string                   `json:"SentimentDetector"`
+	FilterRules       *usecase.FilterRulesInput `json:"FilterRules"`
+}
+
+func (h *handler) updateSearch() lmdrouter.Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (
+		res events.APIGatewayProxyResponse,
+		err error,
+	) {
+		var input updateSearchInput
+		err = lmdrouter.UnmarshalRequest(req, true, &input)
+		if err != nil {
+			return lmdrouter.HandleError(err)
+		}
+
+		u := h.registry.NewSearchUsecase()
+		search, err := u.UpdateUserSearch(ctx, &usecase.SearchUsecaseUpdateInput{
+			SearchID:          input.SearchID,
+			Title:             input.Title,
+			Query:             input.Query,
+			IntervalMinutes:   input.IntervalMinutes,
+			AdaptiveInterval:  input.AdaptiveInterval,
+			SentimentDetector: input.SentimentDetector,
+			FilterRules:       input.FilterRules,
+		})
+		var errv validator.ErrValidation
+		if errors.As(err, &errv) {
+			return h.handleValidationErrors(errv)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return lmdrouter.HandleError(lmdrouter.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "specified search was not found",
+			})
+		}
+		if errors.Is(err, usecase.ErrSearchDeleting) {
+			return lmdrouter.HandleError(lmdrouter.HTTPError{
+				Code:    http.StatusConflict,
+				Message: "specified search is being deleted",
+			})
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			return lmdrouter.HandleError(lmdrouter.HTTPError{
+				Code:    http.StatusConflict,
+				Message: "query of specified search was changed by others",
+			})
+		}
+		var qerr *usecase.QuotaExceededError
+		if errors.As(err, &qerr) {
+			return h.handleQuotaExceededError(qerr)
+		}
+		if err != nil {
+			return lmdrouter.HandleError(err)
+		}
+
+		return lmdrouter.MarshalResponse(http.StatusOK, nil, search)
+	}
+}
+
+type changeSearchStatusInput struct {
+	SearchID model.SearchID `lambda:"path.id"`
+}
+
+func (h *handler) changeSearchStatus(status model.SearchStatus) lmdrouter.Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (
+		res events.APIGatewayProxyResponse,
+		err error,
+	) {
+		var input changeSearchStatusInput
+		err = lmdrouter.UnmarshalRequest(req, false, &input)
+		if err != nil {
+			return lmdrouter.HandleError(err)
+		}
+
+		u := h.registry.NewSearchUsecase()
+		search, err := u.ChangeUserSearchStatus(ctx, input.SearchID, status)
+		if errors.Is(err, repository.ErrNotFound) {
+			return lmdrouter.HandleError(lmdrouter.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "specified search was not found",
+			})
+		}
+		if errors.Is(err, usecase.ErrSearchDeleting) {
+			return lmdrouter.HandleError(lmdrouter.HTTPError{
+				Code:    http.StatusConflict,
+				Message: "specified search is being deleted",
+			})
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			return lmdrouter.HandleError(lmdrouter.HTTPError{
+				Code:    http.StatusConflict,
+				Message: "status of specified search was changed by others",
+			})
+		}
+		var qerr *usecase.QuotaExceededError
+		if errors.As(err, &qerr) {
+			return h.handleQuotaExceededError(qerr)
+		}
+		if err != nil {
+			return lmdrouter.HandleError(err)
+		}
+
+		return lmdrouter.MarshalResponse(http.StatusOK, nil, search)
+	}
+}
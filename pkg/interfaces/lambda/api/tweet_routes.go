@@ -2,18 +2,23 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/aquasecurity/lmdrouter"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/hareku/emosearch-api/pkg/domain/model"
 	"github.com/hareku/emosearch-api/pkg/domain/repository"
 	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
+	"github.com/hareku/emosearch-api/pkg/domain/validator"
+	"github.com/hareku/emosearch-api/pkg/usecase"
 )
 
 func (h *handler) registerTweetRoutes() {
 	h.router.Route("GET", "/searches/:search_id/tweets", h.fetchTweets())
+	h.router.Route("GET", "/searches/:search_id/sentiment/timeline", h.fetchSentimentTimeline())
 }
 
 type fetchTweetsInput struct {
@@ -21,6 +26,7 @@ type fetchTweetsInput struct {
 	UntilID        model.TweetID  `lambda:"query.until_id"`
 	Limit          int64          `lambda:"query.limit"`
 	SentimentLabel string         `lambda:"query.sentiment_label"`
+	Emotion        string         `lambda:"query.emotion"`
 }
 
 type fetchTweetsRes struct {
@@ -62,6 +68,16 @@ func (h *handler) fetchTweets() lmdrouter.Handler {
 			label := sentiment.Label(input.SentimentLabel)
 			listInput.SentimentLabel = &label
 		}
+		if input.Emotion != "" {
+			emotion := sentiment.Emotion(input.Emotion)
+			if !emotion.IsValid() {
+				return lmdrouter.HandleError(lmdrouter.HTTPError{
+					Code:    http.StatusBadRequest,
+					Message: fmt.Sprintf("emotion must be one of %v", sentiment.Emotions),
+				})
+			}
+			listInput.DominantEmotion = &emotion
+		}
 
 		tweets, err := r.List(ctx, listInput)
 		if err != nil {
@@ -80,3 +96,92 @@ func (h *handler) fetchTweets() lmdrouter.Handler {
 		return lmdrouter.MarshalResponse(http.StatusOK, nil, pagination)
 	}
 }
+
+type fetchSentimentTimelineInput struct {
+	SearchID model.SearchID `lambda:"path.search_id"`
+	Bucket   string         `lambda:"query.bucket"`
+	From     string         `lambda:"query.from"`
+	To       string         `lambda:"query.to"`
+}
+
+type fetchSentimentTimelineRes struct {
+	Bucket  model.TimelineBucket
+	Buckets []model.SentimentTimelineBucket
+}
+
+func (h *handler) fetchSentimentTimeline() lmdrouter.Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (
+		res events.APIGatewayProxyResponse,
+		err error,
+	) {
+		var input fetchSentimentTimelineInput
+		err = lmdrouter.UnmarshalRequest(req, false, &input)
+		if err != nil {
+			return lmdrouter.HandleError(fmt.Errorf("failed to parse input: %w", err))
+		}
+
+		ucInput := &usecase.TweetUsecaseSentimentTimelineInput{
+			SearchID: input.SearchID,
+			Bucket:   model.TimelineBucket(input.Bucket),
+		}
+		if ucInput.Bucket == "" {
+			ucInput.Bucket = model.TimelineBucketHour
+		}
+		if ucInput.From, err = parseTimeParam("from", input.From); err != nil {
+			return lmdrouter.HandleError(err)
+		}
+		if ucInput.To, err = parseTimeParam("to", input.To); err != nil {
+			return lmdrouter.HandleError(err)
+		}
+
+		u := h.registry.NewTweetUsecase()
+		buckets, err := u.SentimentTimeline(ctx, ucInput)
+		var errv validator.ErrValidation
+		if errors.As(err, &errv) {
+			return h.handleValidationErrors(errv)
+		}
+		if errors.Is(err, usecase.ErrTooManyTimelineBuckets) {
+			return lmdrouter.HandleError(lmdrouter.HTTPError{
+				Code:    http.StatusBadRequest,
+				Message: "specified range is too wide for the bucket",
+			})
+		}
+		if errors.Is(err, usecase.ErrTooManyTimelineTweets) {
+			return lmdrouter.HandleError(lmdrouter.HTTPError{
+				Code:    http.StatusBadRequest,
+				Message: "specified range has too many tweets for the hour bucket, narrow the range or use the day bucket",
+			})
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return lmdrouter.HandleError(lmdrouter.HTTPError{
+				Code:    http.StatusNotFound,
+				Message: "specified search was not found",
+			})
+		}
+		if err != nil {
+			return lmdrouter.HandleError(fmt.Errorf("failed to fetch sentiment timeline: %w", err))
+		}
+
+		return lmdrouter.MarshalResponse(http.StatusOK, nil, fetchSentimentTimelineRes{
+			Bucket:  ucInput.Bucket,
+			Buckets: buckets,
+		})
+	}
+}
+
+// parseTimeParam parses RFC3339 query parameter, and returns zero time if the parameter is empty.
+func parseTimeParam(name string, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, lmdrouter.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("%s must be RFC3339 format", name),
+		}
+	}
+
+	return t, nil
+}
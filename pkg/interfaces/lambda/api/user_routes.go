@@ -14,6 +14,7 @@ import (
 func (h *handler) registerUserRoutes() {
 	h.router.Route("GET", "/users/@me", h.fetchMe())
 	h.router.Route("POST", "/users/@me", h.registerUser())
+	h.router.Route("GET", "/users/@me/quota", h.fetchMyQuota())
 }
 
 func (h *handler) fetchMe() lmdrouter.Handler {
@@ -69,3 +70,18 @@ func (h *handler) registerUser() lmdrouter.Handler {
 		return lmdrouter.MarshalResponse(http.StatusCreated, nil, user)
 	}
 }
+
+func (h *handler) fetchMyQuota() lmdrouter.Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (
+		res events.APIGatewayProxyResponse,
+		err error,
+	) {
+		u := h.registry.NewUserUsecase()
+		status, err := u.GetAuthUserQuota(ctx)
+		if err != nil {
+			return lmdrouter.HandleError(err)
+		}
+
+		return lmdrouter.MarshalResponse(http.StatusOK, nil, status)
+	}
+}
@@ -0,0 +1,55 @@
+package job
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+	"github.com/hareku/emosearch-api/pkg/registry"
+)
+
+type handler struct {
+	registry registry.Registry
+}
+
+// Handler provides the gate of AWS Lambda for asynchronous jobs.
+type Handler interface {
+	StartDeleteSearch()
+	StartBackfillTweets()
+	StartRescoreTweets()
+}
+
+// New returns an instance of Handler.
+func New(registry registry.Registry) Handler {
+	return &handler{registry}
+}
+
+// SearchEvent is the event of jobs which process a search.
+type SearchEvent struct {
+	SearchID model.SearchID `json:"search_id"`
+	UserID   model.UserID   `json:"user_id"`
+}
+
+func (h *handler) StartDeleteSearch() {
+	lambda.Start(h.deleteSearchHandler)
+}
+
+func (h *handler) deleteSearchHandler(ctx context.Context, event SearchEvent) error {
+	return h.registry.NewBatchUsecase().DeleteSearch(ctx, event.SearchID, event.UserID)
+}
+
+func (h *handler) StartBackfillTweets() {
+	lambda.Start(h.backfillTweetsHandler)
+}
+
+func (h *handler) backfillTweetsHandler(ctx context.Context, event SearchEvent) error {
+	return h.registry.NewBatchUsecase().BackfillTweets(ctx, event.SearchID, event.UserID)
+}
+
+func (h *handler) StartRescoreTweets() {
+	lambda.Start(h.rescoreTweetsHandler)
+}
+
+func (h *handler) rescoreTweetsHandler(ctx context.Context, event model.TweetRescoring) (*model.TweetRescoring, error) {
+	return h.registry.NewBatchUsecase().RescoreTweets(ctx, &event)
+}
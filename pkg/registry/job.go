@@ -0,0 +1,37 @@
+package registry
+
+import (
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/hareku/emosearch-api/pkg/domain/job"
+	"github.com/hareku/emosearch-api/pkg/infrastructure/awslambda"
+)
+
+var lambdaClient *lambda.Lambda
+
+func getLambdaClient() *lambda.Lambda {
+	if lambdaClient == nil {
+		awsConf := &aws.Config{
+			Region: aws.String("ap-northeast-1"),
+		}
+
+		if region := os.Getenv("AWS_REGION"); region != "" {
+			awsConf.Region = aws.String(region)
+		}
+
+		lambdaClient = lambda.New(session.New(), awsConf)
+	}
+
+	return lambdaClient
+}
+
+func (r *registry) NewJobDispatcher() job.Dispatcher {
+	return awslambda.NewLambdaDispatcher(getLambdaClient(), awslambda.LambdaDispatcherFunctions{
+		DeleteSearch:   os.Getenv("DELETE_SEARCH_FUNCTION_NAME"),
+		BackfillTweets: os.Getenv("BACKFILL_TWEETS_FUNCTION_NAME"),
+		RescoreTweets:  os.Getenv("RESCORE_TWEETS_FUNCTION_NAME"),
+	})
+}
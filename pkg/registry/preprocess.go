@@ -0,0 +1,65 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hareku/emosearch-api/pkg/domain/preprocess"
+)
+
+func (r *registry) NewTextPreprocessor() preprocess.Preprocessor {
+	options, err := getTextPreprocessOptions()
+	if err != nil {
+		panic(fmt.Errorf("text preprocessor initialization error: %w", err))
+	}
+	return preprocess.NewPreprocessor(options)
+}
+
+// getTextPreprocessOptions returns the options of TEXT_PREPROCESSING env, which is a comma separated list of options
+// which override the defaults (e.g. "urls=replace,mentions=keep,hashtags=strip,width=false,emoji=true").
+// Entity options are "keep", "strip" or "replace".
+func getTextPreprocessOptions() (preprocess.Options, error) {
+	options := preprocess.DefaultOptions()
+	entityOptions := map[string]*preprocess.EntityAction{
+		"urls":     &options.URLs,
+		"mentions": &options.Mentions,
+		"media":    &options.Media,
+		"hashtags": &options.HashTags,
+	}
+	boolOptions := map[string]*bool{
+		"width": &options.NormalizeWidth,
+		"emoji": &options.NormalizeEmoji,
+	}
+
+	for _, item := range strings.Split(os.Getenv("TEXT_PREPROCESSING"), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		sep := strings.Index(item, "=")
+		if sep < 0 {
+			return options, fmt.Errorf("invalid text preprocessing option: %q", item)
+		}
+		key, value := item[:sep], item[sep+1:]
+
+		if action, ok := entityOptions[key]; ok {
+			switch a := preprocess.EntityAction(value); a {
+			case preprocess.EntityKeep, preprocess.EntityStrip, preprocess.EntityReplace:
+				*action = a
+				continue
+			}
+		}
+		if b, ok := boolOptions[key]; ok {
+			if v, err := strconv.ParseBool(value); err == nil {
+				*b = v
+				continue
+			}
+		}
+		return options, fmt.Errorf("invalid text preprocessing option: %q", item)
+	}
+
+	return options, nil
+}
@@ -2,6 +2,8 @@ package registry
 
 import (
 	"github.com/hareku/emosearch-api/pkg/domain/auth"
+	"github.com/hareku/emosearch-api/pkg/domain/job"
+	"github.com/hareku/emosearch-api/pkg/domain/preprocess"
 	"github.com/hareku/emosearch-api/pkg/domain/repository"
 	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
 	"github.com/hareku/emosearch-api/pkg/domain/twitter"
@@ -15,12 +17,21 @@ type Registry interface {
 	NewUserRepository() repository.UserRepository
 	NewSearchRepository() repository.SearchRepository
 	NewTweetRepository() repository.TweetRepository
+	NewSearchStatsRepository() repository.SearchStatsRepository
+	NewUserUsageRepository() repository.UserUsageRepository
+	NewCollectionRunRepository() repository.CollectionRunRepository
+	NewTweetRescoringRepository() repository.TweetRescoringRepository
+	NewFilterRulesRepository() repository.FilterRulesRepository
 	NewUserUsecase() usecase.UserUsecase
 	NewSearchUsecase() usecase.SearchUsecase
+	NewTweetUsecase() usecase.TweetUsecase
+	NewFilterRulesUsecase() usecase.FilterRulesUsecase
 	NewBatchUsecase() usecase.BatchUsecase
 	NewTwitterClient() twitter.Client
-	NewSentimentDetector() sentiment.Detector
+	NewSentimentDetectorProvider() sentiment.DetectorProvider
+	NewTextPreprocessor() preprocess.Preprocessor
 	NewValidator() validator.Validator
+	NewJobDispatcher() job.Dispatcher
 }
 
 type registry struct{}
@@ -10,10 +10,11 @@ import (
 	"github.com/hareku/emosearch-api/pkg/infrastructure/dynamodb"
 )
 
+var dynamoDB *dynamo.DB
 var dynamoTable *dynamo.Table
 
-func getDynamoTable() *dynamo.Table {
-	if dynamoTable == nil {
+func getDynamoDB() *dynamo.DB {
+	if dynamoDB == nil {
 		awsConf := &aws.Config{
 			Region: aws.String("ap-northeast-1"),
 		}
@@ -26,8 +27,15 @@ func getDynamoTable() *dynamo.Table {
 			awsConf.Endpoint = aws.String(endpoint)
 		}
 
-		dynamoDB := dynamo.New(session.New(), awsConf)
-		table := dynamoDB.Table("EmoSearchAPI")
+		dynamoDB = dynamo.New(session.New(), awsConf)
+	}
+
+	return dynamoDB
+}
+
+func getDynamoTable() *dynamo.Table {
+	if dynamoTable == nil {
+		table := getDynamoDB().Table("EmoSearchAPI")
 		dynamoTable = &table
 	}
 
@@ -43,5 +51,25 @@ func (r *registry) NewSearchRepository() repository.SearchRepository {
 }
 
 func (r *registry) NewTweetRepository() repository.TweetRepository {
-	return dynamodb.NewDynamoDBTweetRepository(*getDynamoTable())
+	return dynamodb.NewDynamoDBTweetRepository(getDynamoDB(), *getDynamoTable())
+}
+
+func (r *registry) NewSearchStatsRepository() repository.SearchStatsRepository {
+	return dynamodb.NewDynamoDBSearchStatsRepository(*getDynamoTable())
+}
+
+func (r *registry) NewUserUsageRepository() repository.UserUsageRepository {
+	return dynamodb.NewDynamoDBUserUsageRepository(*getDynamoTable())
+}
+
+func (r *registry) NewCollectionRunRepository() repository.CollectionRunRepository {
+	return dynamodb.NewDynamoDBCollectionRunRepository(*getDynamoTable())
+}
+
+func (r *registry) NewTweetRescoringRepository() repository.TweetRescoringRepository {
+	return dynamodb.NewDynamoDBTweetRescoringRepository(*getDynamoTable())
+}
+
+func (r *registry) NewFilterRulesRepository() repository.FilterRulesRepository {
+	return dynamodb.NewDynamoDBFilterRulesRepository(*getDynamoTable())
 }
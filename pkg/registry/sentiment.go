@@ -1,10 +1,224 @@
 package registry
 
 import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/comprehend"
 	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
 	"github.com/hareku/emosearch-api/pkg/infrastructure/bayes"
+	awscomprehend "github.com/hareku/emosearch-api/pkg/infrastructure/comprehend"
+	"github.com/hareku/emosearch-api/pkg/infrastructure/dynamodb"
+	"github.com/hareku/emosearch-api/pkg/infrastructure/lexicon"
+	"github.com/hareku/emosearch-api/pkg/infrastructure/memory"
 )
 
-func (r *registry) NewSentimentDetector() sentiment.Detector {
-	return bayes.NewBayesDetector()
+var comprehendClient *comprehend.Comprehend
+
+func getComprehendClient() *comprehend.Comprehend {
+	if comprehendClient == nil {
+		awsConf := &aws.Config{
+			Region: aws.String("ap-northeast-1"),
+		}
+
+		if region := os.Getenv("AWS_REGION"); region != "" {
+			awsConf.Region = aws.String(region)
+		}
+
+		comprehendClient = comprehend.New(session.New(), awsConf)
+	}
+
+	return comprehendClient
+}
+
+var lruSentimentCache sentiment.Cache
+
+// getSentimentCache returns the cache of SENTIMENT_CACHE env, which is "memory", "dynamodb" or empty for no cache.
+// The size of "memory" cache is SENTIMENT_CACHE_SIZE env.
+func getSentimentCache() (sentiment.Cache, error) {
+	switch os.Getenv("SENTIMENT_CACHE") {
+	case "":
+		return nil, nil
+	case "memory":
+		if lruSentimentCache == nil {
+			size := 10000
+			if v := os.Getenv("SENTIMENT_CACHE_SIZE"); v != "" {
+				n, err := strconv.Atoi(v)
+				if err != nil || n <= 0 {
+					return nil, fmt.Errorf("invalid sentiment cache size: %q", v)
+				}
+				size = n
+			}
+			lruSentimentCache = memory.NewLRUSentimentCache(size)
+		}
+		return lruSentimentCache, nil
+	case "dynamodb":
+		return dynamodb.NewDynamoDBSentimentCache(*getDynamoTable()), nil
+	}
+	return nil, fmt.Errorf("unknown sentiment cache: %q", os.Getenv("SENTIMENT_CACHE"))
+}
+
+// sentimentDetectorProvider selects detectors by SENTIMENT_DETECTOR and SENTIMENT_FALLBACK_DETECTOR env.
+// The ensemble detector is configured by SENTIMENT_ENSEMBLE_DETECTORS env, which is a comma separated list of
+// detectors with optional weights (e.g. "bayes:1,comprehend:2,lexicon"), and SENTIMENT_ENSEMBLE_STRATEGY env.
+// Outputs of all detectors are labeled by the policy of SENTIMENT_LABEL_MIN_CONFIDENCE, SENTIMENT_LABEL_MIN_MARGIN
+// and SENTIMENT_LABEL_MIXED_MIN_SCORE env, which are zero by default.
+type sentimentDetectorProvider struct {
+	defaultName      sentiment.DetectorName
+	fallbackName     sentiment.DetectorName
+	ensembleMembers  string
+	ensembleStrategy sentiment.EnsembleStrategy
+	labelPolicy      sentiment.LabelPolicy
+	labelPolicyErr   error
+	// newBaseDetector creates the detector of the name, and returns the version of its model.
+	newBaseDetector func(name sentiment.DetectorName) (sentiment.Detector, string, error)
+}
+
+func (r *registry) NewSentimentDetectorProvider() sentiment.DetectorProvider {
+	p := &sentimentDetectorProvider{
+		defaultName:      sentiment.DetectorName(os.Getenv("SENTIMENT_DETECTOR")),
+		fallbackName:     sentiment.DetectorName(os.Getenv("SENTIMENT_FALLBACK_DETECTOR")),
+		ensembleMembers:  os.Getenv("SENTIMENT_ENSEMBLE_DETECTORS"),
+		ensembleStrategy: sentiment.EnsembleStrategy(os.Getenv("SENTIMENT_ENSEMBLE_STRATEGY")),
+		newBaseDetector:  newSentimentDetector,
+	}
+	if p.defaultName == "" {
+		p.defaultName = sentiment.DetectorBayes
+	}
+	if p.ensembleStrategy == "" {
+		p.ensembleStrategy = sentiment.EnsembleWeightedAverage
+	}
+	p.labelPolicy, p.labelPolicyErr = getSentimentLabelPolicy()
+	return p
+}
+
+// getSentimentLabelPolicy returns the label policy of SENTIMENT_LABEL_* env.
+func getSentimentLabelPolicy() (sentiment.LabelPolicy, error) {
+	policy := sentiment.DefaultLabelPolicy
+	thresholds := []struct {
+		env   string
+		value *float64
+	}{
+		{"SENTIMENT_LABEL_MIN_CONFIDENCE", &policy.MinConfidence},
+		{"SENTIMENT_LABEL_MIN_MARGIN", &policy.MinMargin},
+		{"SENTIMENT_LABEL_MIXED_MIN_SCORE", &policy.MixedMinScore},
+	}
+	for _, t := range thresholds {
+		v := os.Getenv(t.env)
+		if v == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 || f > 1 {
+			return policy, fmt.Errorf("invalid %s: %q", t.env, v)
+		}
+		*t.value = f
+	}
+	return policy, nil
+}
+
+func (p *sentimentDetectorProvider) Detector(name sentiment.DetectorName) (sentiment.Detector, error) {
+	if name == "" {
+		name = p.defaultName
+	}
+	if p.labelPolicyErr != nil {
+		return nil, p.labelPolicyErr
+	}
+	primary, modelVersion, err := p.newDetector(name)
+	if err != nil {
+		return nil, err
+	}
+	cache, err := getSentimentCache()
+	if err != nil {
+		return nil, err
+	}
+	if cache != nil {
+		primary = sentiment.NewCachedDetector(primary, cache, p.cacheNamespace(name, modelVersion))
+	}
+	if p.fallbackName == "" || p.fallbackName == name {
+		return primary, nil
+	}
+
+	fallback, _, err := p.newDetector(p.fallbackName)
+	if err != nil {
+		return nil, err
+	}
+	return sentiment.NewFallbackDetector(primary, fallback), nil
+}
+
+// cacheNamespace returns the namespace of cached outputs of the detector, which changes when the model is updated,
+// or the ensemble or the label policy is reconfigured.
+func (p *sentimentDetectorProvider) cacheNamespace(name sentiment.DetectorName, modelVersion string) string {
+	if name == sentiment.DetectorEnsemble {
+		return fmt.Sprintf("%s:%s:%s:%s:%s", name, p.ensembleStrategy, p.ensembleMembers, modelVersion, p.labelPolicy)
+	}
+	return fmt.Sprintf("%s:%s:%s", name, modelVersion, p.labelPolicy)
+}
+
+// newDetector creates the detector whose outputs are labeled by the label policy, and returns the version of its model.
+func (p *sentimentDetectorProvider) newDetector(name sentiment.DetectorName) (sentiment.Detector, string, error) {
+	if name == sentiment.DetectorEnsemble {
+		return p.newEnsembleDetector()
+	}
+	d, modelVersion, err := p.newBaseDetector(name)
+	if err != nil {
+		return nil, "", err
+	}
+	return sentiment.NewPolicyDetector(d, p.labelPolicy), modelVersion, nil
+}
+
+// newEnsembleDetector creates the ensemble detector, and returns the versions of models of its detectors.
+func (p *sentimentDetectorProvider) newEnsembleDetector() (sentiment.Detector, string, error) {
+	if p.ensembleStrategy != sentiment.EnsembleWeightedAverage && p.ensembleStrategy != sentiment.EnsembleMajorityVote {
+		return nil, "", fmt.Errorf("unknown ensemble strategy: %q", p.ensembleStrategy)
+	}
+
+	members := []sentiment.EnsembleMember{}
+	versions := []string{}
+	for _, item := range strings.Split(p.ensembleMembers, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, weight := item, 1.0
+		if sep := strings.Index(item, ":"); sep >= 0 {
+			w, err := strconv.ParseFloat(item[sep+1:], 64)
+			if err != nil || w <= 0 {
+				return nil, "", fmt.Errorf("invalid weight of ensemble detector: %q", item)
+			}
+			name, weight = item[:sep], w
+		}
+
+		d, modelVersion, err := p.newBaseDetector(sentiment.DetectorName(name))
+		if err != nil {
+			return nil, "", err
+		}
+		versions = append(versions, fmt.Sprintf("%s:%s", name, modelVersion))
+		members = append(members, sentiment.EnsembleMember{
+			Detector: sentiment.NewPolicyDetector(d, p.labelPolicy),
+			Weight:   weight,
+		})
+	}
+	if len(members) == 0 {
+		return nil, "", fmt.Errorf("detectors of the ensemble are not configured")
+	}
+
+	return sentiment.NewEnsembleDetector(p.ensembleStrategy, members, p.labelPolicy), strings.Join(versions, "+"), nil
+}
+
+func newSentimentDetector(name sentiment.DetectorName) (sentiment.Detector, string, error) {
+	switch name {
+	case sentiment.DetectorBayes:
+		return bayes.NewBayesDetector(), bayes.ModelVersion, nil
+	case sentiment.DetectorComprehend:
+		return awscomprehend.NewComprehendDetector(getComprehendClient()), awscomprehend.ModelVersion, nil
+	case sentiment.DetectorLexicon:
+		return lexicon.NewLexiconDetector(), lexicon.ModelVersion, nil
+	}
+	return nil, "", fmt.Errorf("unknown sentiment detector: %q", name)
 }
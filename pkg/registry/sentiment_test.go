@@ -0,0 +1,134 @@
+package registry
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+	"github.com/hareku/emosearch-api/pkg/domain/preprocess"
+	"github.com/hareku/emosearch-api/pkg/domain/repository"
+	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
+	"github.com/hareku/emosearch-api/pkg/usecase"
+)
+
+// versionedDetector detects every text as negative by the model "v1", and as positive by the others.
+type versionedDetector struct {
+	version string
+}
+
+func (d *versionedDetector) BatchDetect(ctx context.Context, textList []*string) ([]sentiment.DetectOutput, error) {
+	positive, negative, neutral := 0.8, 0.1, 0.1
+	if d.version == "v1" {
+		positive, negative = negative, positive
+	}
+
+	outputs := []sentiment.DetectOutput{}
+	for range textList {
+		outputs = append(outputs, sentiment.DetectOutput{
+			Score:    sentiment.Score{Positive: &positive, Negative: &negative, Neutral: &neutral},
+			Detector: sentiment.DetectorInfo{Name: sentiment.DetectorBayes, ModelVersion: d.version},
+		})
+	}
+	return outputs, nil
+}
+
+type stubSearchRepository struct {
+	repository.SearchRepository
+	search *model.Search
+}
+
+func (r *stubSearchRepository) Find(ctx context.Context, userID model.UserID, searchID model.SearchID) (*model.Search, error) {
+	return r.search, nil
+}
+
+// stubTweetRepository stores tweets of a page in memory.
+type stubTweetRepository struct {
+	repository.TweetRepository
+	tweets []model.Tweet
+}
+
+func (r *stubTweetRepository) List(ctx context.Context, input *repository.TweetRepositoryListInput) ([]model.Tweet, error) {
+	if input.UntilID != 0 {
+		return nil, repository.ErrNotFound
+	}
+	return append([]model.Tweet{}, r.tweets...), nil
+}
+
+func (r *stubTweetRepository) UpdateSentiments(ctx context.Context, updates []*repository.TweetSentimentUpdate) (int, error) {
+	for _, update := range updates {
+		for i := range r.tweets {
+			if r.tweets[i].TweetID == update.Tweet.TweetID {
+				r.tweets[i] = *update.Tweet
+			}
+		}
+	}
+	return len(updates), nil
+}
+
+// stubTweetRescoringRepository keeps the stored reports by their start time.
+type stubTweetRescoringRepository struct {
+	repository.TweetRescoringRepository
+	reports map[time.Time]model.TweetRescoring
+}
+
+func (r *stubTweetRescoringRepository) Store(ctx context.Context, rescoring *model.TweetRescoring) error {
+	r.reports[rescoring.StartedAt] = *rescoring
+	return nil
+}
+
+func Test_sentimentDetectorProvider_RescoreAfterModelUpdate(t *testing.T) {
+	os.Setenv("SENTIMENT_CACHE", "memory")
+	defer os.Unsetenv("SENTIMENT_CACHE")
+	lruSentimentCache = nil
+	defer func() { lruSentimentCache = nil }()
+
+	search := &model.Search{SearchID: "search", UserID: "user"}
+	rescorings := &stubTweetRescoringRepository{reports: map[time.Time]model.TweetRescoring{}}
+	tweets := &stubTweetRepository{tweets: []model.Tweet{
+		{TweetID: 2, SearchID: "search", Text: "楽しい"},
+		{TweetID: 1, SearchID: "search", Text: "楽しい"},
+	}}
+
+	rescore := func(version string) *model.TweetRescoring {
+		provider := &sentimentDetectorProvider{
+			defaultName: sentiment.DetectorBayes,
+			labelPolicy: sentiment.DefaultLabelPolicy,
+			newBaseDetector: func(name sentiment.DetectorName) (sentiment.Detector, string, error) {
+				return &versionedDetector{version}, version, nil
+			},
+		}
+		u := usecase.NewBatchUsecase(&usecase.NewBatchUsecaseInput{
+			SearchRepository:         &stubSearchRepository{search: search},
+			TweetRepository:          tweets,
+			TweetRescoringRepository: rescorings,
+			SentimentDetectors:       provider,
+			TextPreprocessor:         preprocess.NewPreprocessor(preprocess.DefaultOptions()),
+		})
+
+		report, err := u.RescoreTweets(context.Background(), &model.TweetRescoring{SearchID: "search", UserID: "user"})
+		if err != nil {
+			t.Fatalf("RescoreTweets returned error: %s", err)
+		}
+		return report
+	}
+
+	if report := rescore("v1"); report.ChangedLabels != 2 || tweets.tweets[0].SentimentLabel != sentiment.LabelNegative {
+		t.Fatalf("re-scoring by v1 changed %d labels into %q, want 2 negative labels", report.ChangedLabels, tweets.tweets[0].SentimentLabel)
+	}
+
+	// Outputs of the previous model are cached, but they must not be served to the updated model.
+	report := rescore("v2")
+	if report.ChangedLabels != 2 || report.LabelChanges["NEGATIVE->POSITIVE"] != 2 {
+		t.Errorf("re-scoring by v2 changed labels %v, want 2 NEGATIVE->POSITIVE", report.LabelChanges)
+	}
+	if stored, ok := rescorings.reports[report.StartedAt]; !ok || !stored.Completed || stored.ChangedLabels != 2 || len(rescorings.reports) != 2 {
+		t.Errorf("stored reports = %+v, want the completed report of each re-scoring", rescorings.reports)
+	}
+	for _, tweet := range tweets.tweets {
+		if tweet.SentimentLabel != sentiment.LabelPositive || tweet.SentimentDetector.ModelVersion != "v2" {
+			t.Errorf("tweet (id: %d) is labeled %q by %+v, want positive by v2", tweet.TweetID, tweet.SentimentLabel, tweet.SentimentDetector)
+		}
+	}
+}
@@ -70,6 +70,14 @@ func getTwitterConsumerSecret() (*string, error) {
 }
 
 // NewTwitterClient create Client of domain Twitter.
+// TWITTER_API_VERSION env selects the API, "1.1" (default) for the standard search and "2" for the recent search.
 func (r *registry) NewTwitterClient() domain_twitter.Client {
-	return infra_twitter.NewTwitterOauth1Client(getOauth1Config())
+	switch version := os.Getenv("TWITTER_API_VERSION"); version {
+	case "", "1.1":
+		return infra_twitter.NewTwitterOauth1Client(getOauth1Config())
+	case "2":
+		return infra_twitter.NewTwitterV2Client(getOauth1Config(), os.Getenv("TWITTER_API_BASE_URL"))
+	default:
+		panic(fmt.Errorf("unknown twitter api version: %q", version))
+	}
 }
@@ -5,19 +5,45 @@ import (
 )
 
 func (r *registry) NewUserUsecase() usecase.UserUsecase {
-	return usecase.NewUserUsecase(r.NewAuthenticator(), r.NewUserRepository())
+	return usecase.NewUserUsecase(r.NewAuthenticator(), r.NewValidator(), r.NewUserRepository(), r.NewUserUsageRepository(), r.NewSearchRepository())
 }
 
 func (r *registry) NewSearchUsecase() usecase.SearchUsecase {
-	return usecase.NewSearchUsecase(r.NewAuthenticator(), r.NewValidator(), r.NewSearchRepository())
+	return usecase.NewSearchUsecase(&usecase.NewSearchUsecaseInput{
+		Authenticator:            r.NewAuthenticator(),
+		Validator:                r.NewValidator(),
+		SearchRepository:         r.NewSearchRepository(),
+		SearchStatsRepository:    r.NewSearchStatsRepository(),
+		CollectionRunRepository:  r.NewCollectionRunRepository(),
+		TweetRescoringRepository: r.NewTweetRescoringRepository(),
+		UserRepository:           r.NewUserRepository(),
+		UserUsageRepository:      r.NewUserUsageRepository(),
+		JobDispatcher:            r.NewJobDispatcher(),
+	})
+}
+
+func (r *registry) NewFilterRulesUsecase() usecase.FilterRulesUsecase {
+	return usecase.NewFilterRulesUsecase(r.NewAuthenticator(), r.NewValidator(), r.NewFilterRulesRepository())
+}
+
+func (r *registry) NewTweetUsecase() usecase.TweetUsecase {
+	return usecase.NewTweetUsecase(r.NewAuthenticator(), r.NewValidator(), r.NewSearchRepository(), r.NewTweetRepository())
 }
 
 func (r *registry) NewBatchUsecase() usecase.BatchUsecase {
 	return usecase.NewBatchUsecase(&usecase.NewBatchUsecaseInput{
-		UserUsecase:       r.NewUserUsecase(),
-		SearchUsecase:     r.NewSearchUsecase(),
-		TweetRepository:   r.NewTweetRepository(),
-		TwitterClient:     r.NewTwitterClient(),
-		SentimentDetector: r.NewSentimentDetector(),
+		UserUsecase:              r.NewUserUsecase(),
+		SearchUsecase:            r.NewSearchUsecase(),
+		SearchRepository:         r.NewSearchRepository(),
+		TweetRepository:          r.NewTweetRepository(),
+		UserRepository:           r.NewUserRepository(),
+		UserUsageRepository:      r.NewUserUsageRepository(),
+		CollectionRunRepository:  r.NewCollectionRunRepository(),
+		TweetRescoringRepository: r.NewTweetRescoringRepository(),
+		FilterRulesRepository:    r.NewFilterRulesRepository(),
+		TwitterClient:            r.NewTwitterClient(),
+		SentimentDetectors:       r.NewSentimentDetectorProvider(),
+		TextPreprocessor:         r.NewTextPreprocessor(),
+		JobDispatcher:            r.NewJobDispatcher(),
 	})
 }
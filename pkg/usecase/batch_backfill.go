@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+	"github.com/hareku/emosearch-api/pkg/domain/repository"
+	"github.com/hareku/emosearch-api/pkg/domain/twitter"
+)
+
+const (
+	// backfillTimeMargin is the remaining time of the context to stop the backfill,
+	// and to continue it by the next job.
+	backfillTimeMargin = 2 * time.Minute
+
+	// staleBackfillDuration is the duration after which a running backfill without progress is dispatched again.
+	staleBackfillDuration = 30 * time.Minute
+)
+
+// BackfillTweets collects tweets which were posted before the backfill was started, by paging backwards.
+// The cursor is saved after each page, and the backfill is continued by the next job if the context deadline is approaching.
+// It is completed when the search API returns no more tweets, which is about 7 days ago for the standard search.
+func (u *batchUsecase) BackfillTweets(ctx context.Context, searchID model.SearchID, userID model.UserID) error {
+	search, user, input, err := u.prepareSearch(ctx, searchID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Printf("Search (id: %s) was not found, backfill skipped.\n", searchID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("backfill preparation error: %w", err)
+	}
+	// Backfills of searches which are not active are resumed as stale backfills when the searches become active again.
+	if !search.IsActive() || !search.Backfill.IsRunning() {
+		log.Printf("Search (id: %s) does not need backfill, skipped.\n", search.SearchID)
+		return nil
+	}
+
+	backfill := search.Backfill
+	quota := user.CurrentQuota()
+	input.SinceID = 0
+	input.MaxID = int64(backfill.MaxTweetID)
+
+	filter, err := u.newTweetFilter(ctx, search)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backfillTimeMargin {
+			log.Printf("Backfill of search (id: %s) is continued by the next job, %d tweets were stored.\n", search.SearchID, backfill.StoredTweets)
+			return u.jobDispatcher.DispatchSearchBackfill(ctx, search.SearchID, search.UserID)
+		}
+
+		remainingTweets, err := u.quota.remainingTweets(ctx, user.UserID, quota)
+		if err != nil {
+			return fmt.Errorf("failed to check quota: %w", err)
+		}
+		if remainingTweets == 0 {
+			backfill.Finish(model.SearchBackfillAborted, "monthly tweets quota was exceeded", time.Now())
+			return u.saveBackfill(ctx, search)
+		}
+
+		output, err := u.twitterClient.Search(ctx, input)
+		var rateLimitErr *twitter.RateLimitError
+		if errors.As(err, &rateLimitErr) {
+			// The backfill is resumed as a stale backfill by a later collection.
+			log.Printf("Rate limit of user (id: %s) was exceeded, backfill of search (id: %s) stopped until %s.\n", user.UserID, search.SearchID, rateLimitErr.Reset)
+			return u.postponeRateLimitedSearches(ctx, user.UserID, rateLimitErr)
+		}
+		if errors.Is(err, twitter.ErrInvalidToken) {
+			// The backfill is resumed as a stale backfill after the user is reauthorized.
+			log.Printf("Twitter token of user (id: %s) was revoked, backfill of search (id: %s) stopped.\n", user.UserID, search.SearchID)
+			return u.reauth.requireReauthorization(ctx, user)
+		}
+		if err != nil {
+			return fmt.Errorf("twitter search error: %w", err)
+		}
+		tweets := output.Tweets
+		// MaxID option includes itself
+		if len(tweets) > 0 && tweets[0].TweetID == input.MaxID {
+			tweets = tweets[1:]
+		}
+
+		if len(tweets) == 0 {
+			backfill.Finish(model.SearchBackfillCompleted, "", time.Now())
+			log.Printf("Backfill of search (id: %s) was completed, %d tweets were stored.\n", search.SearchID, backfill.StoredTweets)
+			return u.saveBackfill(ctx, search)
+		}
+
+		storedTweets, processed, err := u.storeTweets(ctx, search, filter, tweets, remainingTweets)
+		if err != nil {
+			return err
+		}
+
+		// Tweets which were not stored because of the quota are collected by the next page, and it aborts the backfill.
+		oldest := tweets[processed-1]
+		input.MaxID = oldest.TweetID
+		backfill.MaxTweetID = model.TweetID(oldest.TweetID)
+		backfill.OldestTweetAt = &oldest.CreatedAt
+		backfill.FoundTweets += int64(processed)
+		backfill.StoredTweets += storedTweets
+		backfill.UpdatedAt = time.Now()
+
+		err = u.searchRepository.UpdateBackfill(ctx, search)
+		if errors.Is(err, repository.ErrConflict) {
+			log.Printf("Query of search (id: %s) was changed, backfill stopped.\n", search.SearchID)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save backfill progress: %w", err)
+		}
+
+		if output.RateLimit.Exhausted() {
+			// The next page would fail until the reset, and the backfill is resumed as a stale backfill.
+			log.Printf("Rate limit of user (id: %s) was exhausted, backfill of search (id: %s) stopped until %s.\n", user.UserID, search.SearchID, output.RateLimit.Reset)
+			return u.postponeRateLimitedSearches(ctx, user.UserID, output.RateLimit.Err())
+		}
+	}
+}
+
+// saveBackfill saves the backfill progress, and ignores the conflict of the query.
+func (u *batchUsecase) saveBackfill(ctx context.Context, search *model.Search) error {
+	err := u.searchRepository.UpdateBackfill(ctx, search)
+	if errors.Is(err, repository.ErrConflict) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save backfill progress: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+	"github.com/hareku/emosearch-api/pkg/domain/repository"
+	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
+)
+
+const (
+	// rescoreTweetsPageSize is the number of tweets which are re-scored by a page.
+	rescoreTweetsPageSize = 100
+
+	// rescoreTweetsTimeMargin is the remaining time of the context to stop the re-scoring,
+	// and to continue it by the next job.
+	rescoreTweetsTimeMargin = time.Minute
+)
+
+// RescoreTweets detects sentiments of stored tweets of the search again, from the newest to the oldest,
+// and updates their scores and labels. In dry-run mode, tweets are not updated and only the report is made.
+// If the context deadline is approaching, the re-scoring is continued by the next job with the report so far.
+// The report is stored after each job, so that the progress and the result can be seen without the job output.
+func (u *batchUsecase) RescoreTweets(ctx context.Context, rescoring *model.TweetRescoring) (*model.TweetRescoring, error) {
+	search, err := u.searchRepository.Find(ctx, rescoring.UserID, rescoring.SearchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch search: %w", err)
+	}
+	if search.Status == model.SearchStatusDeleting {
+		log.Printf("Search (id: %s) is being deleted, re-scoring skipped.\n", search.SearchID)
+		return rescoring, nil
+	}
+
+	if rescoring.StartedAt.IsZero() {
+		rescoring.StartedAt = time.Now()
+	}
+	if rescoring.Detector == "" {
+		rescoring.Detector = search.SentimentDetector
+	}
+	detector, err := u.sentimentDetectors.Detector(rescoring.Detector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select sentiment detector: %w", err)
+	}
+
+	for {
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < rescoreTweetsTimeMargin {
+			log.Printf("Re-scoring of search (id: %s) is continued by the next job, %d tweets were scanned.\n", search.SearchID, rescoring.ScannedTweets)
+			u.storeRescoring(ctx, rescoring)
+			err = u.jobDispatcher.DispatchTweetRescoring(ctx, rescoring)
+			if err != nil {
+				return nil, fmt.Errorf("failed to dispatch tweet re-scoring: %w", err)
+			}
+			return rescoring, nil
+		}
+
+		tweets, err := u.tweetRepository.List(ctx, &repository.TweetRepositoryListInput{
+			SearchID: search.SearchID,
+			Limit:    rescoreTweetsPageSize,
+			UntilID:  rescoring.UntilTweetID,
+		})
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("failed to list tweets: %w", err)
+		}
+
+		if len(tweets) > 0 {
+			err = u.rescoreTweets(ctx, detector, rescoring, tweets)
+			if err != nil {
+				return nil, err
+			}
+			rescoring.UntilTweetID = tweets[len(tweets)-1].TweetID
+			rescoring.UpdatedAt = time.Now()
+		}
+
+		if len(tweets) < rescoreTweetsPageSize {
+			rescoring.Complete(time.Now())
+			log.Printf("Re-scoring of search (id: %s) was completed (dry run: %t), %d tweets were scanned and %d labels were changed.\n",
+				search.SearchID, rescoring.DryRun, rescoring.ScannedTweets, rescoring.ChangedLabels)
+			u.storeRescoring(ctx, rescoring)
+			return rescoring, nil
+		}
+	}
+}
+
+// storeRescoring stores the report of the re-scoring. Failures are logged, because the re-scoring itself succeeded.
+func (u *batchUsecase) storeRescoring(ctx context.Context, rescoring *model.TweetRescoring) {
+	err := u.tweetRescoringRepository.Store(ctx, rescoring)
+	if err != nil {
+		log.Printf("Failed to store the report of re-scoring of search (id: %s): %s\n", rescoring.SearchID, err)
+	}
+}
+
+// rescoreTweets detects sentiments of the tweets, and updates them unless it is a dry run.
+// Tweets whose detection failed keep their sentiments.
+func (u *batchUsecase) rescoreTweets(ctx context.Context, detector sentiment.Detector, rescoring *model.TweetRescoring, tweets []model.Tweet) error {
+	textList := []*string{}
+	for i := range tweets {
+		tweets[i].NormalizedText = u.textPreprocessor.Preprocess(tweets[i].Text, tweets[i].Entities)
+		textList = append(textList, &tweets[i].NormalizedText)
+	}
+
+	detectOutputs, err := batchDetectTexts(ctx, detector, textList)
+	if err != nil {
+		return fmt.Errorf("failed to batch detect sentiment score: %w", err)
+	}
+
+	updates := []*repository.TweetSentimentUpdate{}
+	for i := range tweets {
+		tweet := &tweets[i]
+		detectOutput := &detectOutputs[i]
+		rescoring.ScannedTweets++
+		if detectOutput.Err != nil {
+			log.Printf("Failed to detect sentiment of tweet (id: %d), it is not updated: %s\n", tweet.TweetID, detectOutput.Err)
+			rescoring.FailedTweets++
+			continue
+		}
+
+		previousLabel := tweet.SentimentLabel
+		if previousLabel != detectOutput.Label {
+			rescoring.AddLabelChange(previousLabel, detectOutput.Label)
+		}
+
+		tweet.SentimentScore = &detectOutput.Score
+		tweet.SentimentLabel = detectOutput.Label
+		tweet.SentimentDetector = &detectOutput.Detector
+		if tweet.NormalizedText == "" {
+			// Empty texts are not detected, and they are labeled as unknown.
+			tweet.SentimentScore, tweet.SentimentDetector = nil, nil
+		}
+		tweet.SentimentConfidence = detectOutput.Confidence
+		tweet.Emotions = detectOutput.Emotions
+		tweet.DominantEmotion = dominantEmotion(detectOutput.Emotions)
+		updates = append(updates, &repository.TweetSentimentUpdate{
+			Tweet:         tweet,
+			PreviousLabel: previousLabel,
+		})
+	}
+
+	if rescoring.DryRun || len(updates) == 0 {
+		return nil
+	}
+
+	updated, err := u.tweetRepository.UpdateSentiments(ctx, updates)
+	if err != nil {
+		return fmt.Errorf("failed to update sentiments of tweets: %w", err)
+	}
+	rescoring.UpdatedTweets += int64(updated)
+
+	return nil
+}
@@ -5,10 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"strings"
 	"time"
 
+	"github.com/hareku/emosearch-api/pkg/domain/job"
 	"github.com/hareku/emosearch-api/pkg/domain/model"
+	"github.com/hareku/emosearch-api/pkg/domain/preprocess"
 	"github.com/hareku/emosearch-api/pkg/domain/repository"
 	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
 	"github.com/hareku/emosearch-api/pkg/domain/twitter"
@@ -17,145 +18,528 @@ import (
 // BatchUsecase provides usecases of Batch domain.
 type BatchUsecase interface {
 	CollectTweets(ctx context.Context, searchID model.SearchID, userID model.UserID) error
+	DeleteSearch(ctx context.Context, searchID model.SearchID, userID model.UserID) error
+	BackfillTweets(ctx context.Context, searchID model.SearchID, userID model.UserID) error
+	RescoreTweets(ctx context.Context, rescoring *model.TweetRescoring) (*model.TweetRescoring, error)
 }
 
 type batchUsecase struct {
-	userUsecase       UserUsecase
-	searchUsecase     SearchUsecase
-	tweetRepository   repository.TweetRepository
-	twitterClient     twitter.Client
-	sentimentDetector sentiment.Detector
+	userUsecase              UserUsecase
+	searchUsecase            SearchUsecase
+	searchRepository         repository.SearchRepository
+	tweetRepository          repository.TweetRepository
+	collectionRunRepository  repository.CollectionRunRepository
+	tweetRescoringRepository repository.TweetRescoringRepository
+	filterRulesRepository    repository.FilterRulesRepository
+	twitterClient            twitter.Client
+	sentimentDetectors       sentiment.DetectorProvider
+	textPreprocessor         preprocess.Preprocessor
+	jobDispatcher            job.Dispatcher
+	quota                    *quotaChecker
+	reauth                   *reauthorizer
 }
 
 // NewBatchUsecaseInput is the input of NewBatchUsecase.
 type NewBatchUsecaseInput struct {
-	UserUsecase       UserUsecase
-	SearchUsecase     SearchUsecase
-	TweetRepository   repository.TweetRepository
-	TwitterClient     twitter.Client
-	SentimentDetector sentiment.Detector
+	UserUsecase              UserUsecase
+	SearchUsecase            SearchUsecase
+	SearchRepository         repository.SearchRepository
+	TweetRepository          repository.TweetRepository
+	UserRepository           repository.UserRepository
+	UserUsageRepository      repository.UserUsageRepository
+	CollectionRunRepository  repository.CollectionRunRepository
+	TweetRescoringRepository repository.TweetRescoringRepository
+	FilterRulesRepository    repository.FilterRulesRepository
+	TwitterClient            twitter.Client
+	SentimentDetectors       sentiment.DetectorProvider
+	TextPreprocessor         preprocess.Preprocessor
+	JobDispatcher            job.Dispatcher
 }
 
 // NewBatchUsecase creates BatchUsecase.
 func NewBatchUsecase(input *NewBatchUsecaseInput) BatchUsecase {
 	return &batchUsecase{
-		userUsecase:       input.UserUsecase,
-		searchUsecase:     input.SearchUsecase,
-		tweetRepository:   input.TweetRepository,
-		twitterClient:     input.TwitterClient,
-		sentimentDetector: input.SentimentDetector,
+		userUsecase:              input.UserUsecase,
+		searchUsecase:            input.SearchUsecase,
+		searchRepository:         input.SearchRepository,
+		tweetRepository:          input.TweetRepository,
+		collectionRunRepository:  input.CollectionRunRepository,
+		tweetRescoringRepository: input.TweetRescoringRepository,
+		filterRulesRepository:    input.FilterRulesRepository,
+		twitterClient:            input.TwitterClient,
+		sentimentDetectors:       input.SentimentDetectors,
+		textPreprocessor:         input.TextPreprocessor,
+		jobDispatcher:            input.JobDispatcher,
+		quota: &quotaChecker{
+			userRepository:      input.UserRepository,
+			userUsageRepository: input.UserUsageRepository,
+			searchRepository:    input.SearchRepository,
+		},
+		reauth: &reauthorizer{
+			userRepository:   input.UserRepository,
+			searchRepository: input.SearchRepository,
+		},
 	}
 }
 
+const (
+	// deleteSearchPageSize is the number of items which are deleted by a page.
+	deleteSearchPageSize = 1000
+
+	// deleteSearchTimeMargin is the remaining time of the context to stop deleting,
+	// and to continue the deletion by the next job.
+	deleteSearchTimeMargin = time.Minute
+)
+
+// DeleteSearch deletes tweets of the search page by page, and deletes the search itself at last.
+// If the context deadline is approaching, the progress is saved and the deletion is continued by the next job.
+func (u *batchUsecase) DeleteSearch(ctx context.Context, searchID model.SearchID, userID model.UserID) error {
+	search, err := u.searchRepository.Find(ctx, userID, searchID)
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Printf("Search (id: %s) was already deleted.\n", searchID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch search: %w", err)
+	}
+	if search.Status != model.SearchStatusDeleting {
+		log.Printf("Search (id: %s) is %s, deletion skipped.\n", search.SearchID, search.Status)
+		return nil
+	}
+
+	for {
+		deletedTweets, done, err := u.tweetRepository.DeleteBySearchID(ctx, search.SearchID, deleteSearchPageSize)
+		if err != nil {
+			return fmt.Errorf("failed to delete tweets of the search: %w", err)
+		}
+		search.DeletedTweetCount += int64(deletedTweets)
+
+		if done {
+			break
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < deleteSearchTimeMargin {
+			log.Printf("Search (id: %s) deletion is continued by the next job, %d tweets were deleted.\n", search.SearchID, search.DeletedTweetCount)
+			err = u.searchRepository.Update(ctx, search)
+			if errors.Is(err, repository.ErrNotFound) {
+				log.Printf("Search (id: %s) was already deleted.\n", search.SearchID)
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to save deletion progress: %w", err)
+			}
+			err = u.jobDispatcher.DispatchSearchDeletion(ctx, search.SearchID, search.UserID)
+			if err != nil {
+				return fmt.Errorf("failed to dispatch search deletion: %w", err)
+			}
+			return nil
+		}
+
+		err = u.searchRepository.Update(ctx, search)
+		if errors.Is(err, repository.ErrNotFound) {
+			log.Printf("Search (id: %s) was already deleted.\n", search.SearchID)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save deletion progress: %w", err)
+		}
+	}
+
+	err = u.searchRepository.Delete(ctx, search)
+	if err != nil {
+		return fmt.Errorf("failed to delete search: %w", err)
+	}
+
+	// Collections which were running when the deletion started may have stored tweets and runs after they were deleted.
+	// They cannot store more pages once the search is deleted, because their progress is saved with conditions on the search.
+	for {
+		deletedTweets, done, err := u.tweetRepository.DeleteBySearchID(ctx, search.SearchID, deleteSearchPageSize)
+		if err != nil {
+			return fmt.Errorf("failed to delete tweets stored during the deletion: %w", err)
+		}
+		search.DeletedTweetCount += int64(deletedTweets)
+		if done {
+			break
+		}
+	}
+	log.Printf("Search (id: %s) was deleted with %d tweets.\n", search.SearchID, search.DeletedTweetCount)
+
+	return nil
+}
+
+// CollectTweets collects new tweets of the search, and records the run.
 func (u *batchUsecase) CollectTweets(ctx context.Context, searchID model.SearchID, userID model.UserID) error {
-	search, input, err := u.prepareSearch(ctx, searchID, userID)
+	run := model.NewCollectionRun(searchID, time.Now())
+	err := u.collectTweets(ctx, searchID, userID, run)
+	run.Finish(time.Now(), err)
+
+	// Runs of searches which do not exist or are being deleted are not recorded,
+	// because nobody can see them, and the deletion may have already passed their keys.
+	if !errors.Is(err, repository.ErrNotFound) && u.searchExists(ctx, searchID, userID) {
+		storeErr := u.collectionRunRepository.Store(ctx, run)
+		if storeErr != nil {
+			log.Printf("Failed to store collection run of search (id: %s): %s\n", searchID, storeErr)
+		}
+	}
+
+	return err
+}
+
+// searchExists reports whether the search exists and is not being deleted.
+// The search is fetched again, because it may be deleted while its tweets are collected.
+func (u *batchUsecase) searchExists(ctx context.Context, searchID model.SearchID, userID model.UserID) bool {
+	search, err := u.searchRepository.Find(ctx, userID, searchID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false
+	}
+	if err != nil {
+		// The run is recorded, because it is more useful than an orphan run is harmful.
+		log.Printf("Failed to fetch search (id: %s): %s\n", searchID, err)
+		return true
+	}
+	return search.Status != model.SearchStatusDeleting
+}
+
+func (u *batchUsecase) collectTweets(ctx context.Context, searchID model.SearchID, userID model.UserID, run *model.CollectionRun) error {
+	search, user, input, err := u.prepareSearch(ctx, searchID, userID)
 	if err != nil {
 		return fmt.Errorf("collect tweets preparation error: %w", err)
 	}
+	if !search.IsActive() {
+		log.Printf("Search (id: %s) is %s, skipped.\n", search.SearchID, search.Status)
+		run.AddNote(fmt.Sprintf("skipped because the search is %s", search.Status))
+		return nil
+	}
+
+	if user.NeedsReauthorization() {
+		log.Printf("User (id: %s) needs reauthorization, search (id: %s) skipped.\n", user.UserID, search.SearchID)
+		run.AddNote("skipped because the twitter token of the user must be reauthorized")
+		return u.reauth.requireReauthorization(ctx, user)
+	}
+
+	u.resumeStaleBackfill(ctx, search)
+
+	quota := user.CurrentQuota()
+	remainingTweets, err := u.quota.remainingTweets(ctx, user.UserID, quota)
+	if err != nil {
+		return fmt.Errorf("failed to check quota: %w", err)
+	}
+	if remainingTweets == 0 {
+		log.Printf("User (id: %s) exceeded the monthly tweets quota, search (id: %s) skipped.\n", user.UserID, search.SearchID)
+		run.AddNote("skipped because the monthly tweets quota was exceeded")
+		// The search is checked again after the interval, because the quota may be raised by an admin.
+		return u.searchUsecase.UpdateNextUpdateAt(ctx, search)
+	}
 
 	err = u.searchUsecase.UpdateNextUpdateAt(ctx, search)
 	if err != nil {
 		return fmt.Errorf("failed to save next search update at: %w", err)
 	}
 
-	err = u.runCollection(ctx, search, input)
+	foundTweets, err := u.runCollection(ctx, search, input, remainingTweets, run)
+	var rateLimitErr *twitter.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		log.Printf("Rate limit of user (id: %s) was exceeded, search (id: %s) stopped until %s.\n", user.UserID, search.SearchID, rateLimitErr.Reset)
+		run.AddNote(fmt.Sprintf("stopped because the rate limit was exceeded until %s", rateLimitErr.Reset.Format(time.RFC3339)))
+		return u.postponeRateLimitedSearches(ctx, user.UserID, rateLimitErr)
+	}
+	if errors.Is(err, twitter.ErrInvalidToken) {
+		log.Printf("Twitter token of user (id: %s) was revoked, searches of the user are paused.\n", user.UserID)
+		run.AddNote("stopped because the twitter token was revoked, searches of the user were paused until reauthorization")
+		return u.reauth.requireReauthorization(ctx, user)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to collect tweets: %w", err)
 	}
 
+	err = u.searchUsecase.ScheduleNextUpdate(ctx, search, quota, foundTweets)
+	if err != nil {
+		return fmt.Errorf("failed to schedule next search update: %w", err)
+	}
+
+	return nil
+}
+
+// resumeStaleBackfill dispatches the backfill job again if the running backfill has not progressed for a while,
+// e.g. the job failed, or it was not dispatched when the search was created.
+func (u *batchUsecase) resumeStaleBackfill(ctx context.Context, search *model.Search) {
+	if !search.Backfill.IsRunning() || time.Since(search.Backfill.UpdatedAt) < staleBackfillDuration {
+		return
+	}
+
+	log.Printf("Backfill of search (id: %s) is stale, dispatched again.\n", search.SearchID)
+	search.Backfill.UpdatedAt = time.Now()
+	err := u.searchRepository.UpdateBackfill(ctx, search)
+	if err == nil {
+		err = u.jobDispatcher.DispatchSearchBackfill(ctx, search.SearchID, search.UserID)
+	}
+	if err != nil && !errors.Is(err, repository.ErrConflict) {
+		log.Printf("Failed to resume backfill of search (id: %s): %s\n", search.SearchID, err)
+	}
+}
+
+// postponeRateLimitedSearches postpones the collections of all searches of the user until the rate limit is reset,
+// because they share the user's token.
+func (u *batchUsecase) postponeRateLimitedSearches(ctx context.Context, userID model.UserID, rateLimitErr *twitter.RateLimitError) error {
+	reason := fmt.Sprintf("rate limit of twitter was exceeded, postponed until %s", rateLimitErr.Reset.Format(time.RFC3339))
+	err := u.searchUsecase.PostponeUserSearches(ctx, userID, rateLimitErr.Reset, reason)
+	if err != nil {
+		return fmt.Errorf("failed to postpone rate limited searches: %w", err)
+	}
 	return nil
 }
 
-func (u *batchUsecase) prepareSearch(ctx context.Context, searchID model.SearchID, userID model.UserID) (*model.Search, *twitter.SearchInput, error) {
+func (u *batchUsecase) prepareSearch(ctx context.Context, searchID model.SearchID, userID model.UserID) (*model.Search, *model.User, *twitter.SearchInput, error) {
 	search, err := u.searchUsecase.Find(ctx, searchID, userID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to fetch search: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to fetch search: %w", err)
 	}
 	if search == nil {
-		return nil, nil, fmt.Errorf("specified search (id: %s) not found: %w", searchID, err)
+		return nil, nil, nil, fmt.Errorf("specified search (id: %s) not found: %w", searchID, repository.ErrNotFound)
 	}
 
 	user, err := u.userUsecase.FindByID(ctx, search.UserID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to fetch user: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if user == nil {
+		return nil, nil, nil, fmt.Errorf("user (id: %s) of the search was not found", search.UserID)
 	}
 
-	latestTweetID, err := u.tweetRepository.LatestTweetID(ctx, search.SearchID)
-	if err != nil && !errors.Is(err, repository.ErrNotFound) {
-		return nil, nil, fmt.Errorf("failed to get latest collected tweet id: %w", err)
+	sinceTweetID, err := u.resolveSinceTweetID(ctx, search)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 	input := &twitter.SearchInput{
 		Query:                    search.Query,
 		TwitterAccessToken:       user.TwitterAccessToken,
 		TwitterAccessTokenSecret: user.TwitterAccessTokenSecret,
-		SinceID:                  int64(latestTweetID),
+		SinceID:                  int64(sinceTweetID),
+	}
+
+	return search, user, input, nil
+}
+
+func (u *batchUsecase) resolveSinceTweetID(ctx context.Context, search *model.Search) (model.TweetID, error) {
+	if search.SinceTweetID != nil {
+		return *search.SinceTweetID, nil
+	}
+
+	latestTweetID, err := u.tweetRepository.LatestTweetID(ctx, search.SearchID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return 0, fmt.Errorf("failed to get latest collected tweet id: %w", err)
 	}
 
-	return search, input, nil
+	return latestTweetID, nil
 }
 
-func (u *batchUsecase) runCollection(ctx context.Context, search *model.Search, input *twitter.SearchInput) error {
-	tweetsBuf := []*twitter.Tweet{}
+// runCollection collects tweets of the search page by page from the newest to the cursor, and returns the number of found tweets.
+// The window being filled is saved as a checkpoint after each page, and the next run resumes it if this run fails.
+// When the window is filled, the cursor moves to the newest tweet of the window.
+// It stops when the number of stored tweets reaches maxStoredTweets, and the rest of the window is filled by a later run.
+func (u *batchUsecase) runCollection(ctx context.Context, search *model.Search, input *twitter.SearchInput, maxStoredTweets int64, run *model.CollectionRun) (int, error) {
+	filter, err := u.newTweetFilter(ctx, search)
+	if err != nil {
+		return 0, err
+	}
+	run.FilteredTweets = filter.dropped
+
+	checkpoint := search.CollectionCheckpoint
+	if checkpoint != nil {
+		log.Printf("Search (id: %s) resumes the collection of tweets in (%d, %d].\n", search.SearchID, checkpoint.SinceTweetID, checkpoint.MaxTweetID)
+		run.AddNote(fmt.Sprintf("resumed the unfilled range of tweet ids (%d, %d] of the previous run", checkpoint.SinceTweetID, checkpoint.MaxTweetID))
+		input.SinceID = int64(checkpoint.SinceTweetID)
+		input.MaxID = int64(checkpoint.MaxTweetID)
+	}
+
+	foundTweets := 0
+	var storedTweets int64
 
 	for {
-		tweets, err := u.twitterClient.Search(ctx, input)
+		if storedTweets >= maxStoredTweets {
+			log.Printf("Search (id: %s) reached the monthly tweets quota while collecting.\n", search.SearchID)
+			run.AddNote("stopped because the monthly tweets quota was reached")
+			return foundTweets, nil
+		}
+
+		output, err := u.twitterClient.Search(ctx, input)
+		var rateLimitErr *twitter.RateLimitError
+		if errors.As(err, &rateLimitErr) {
+			// Progress of the window was saved by the checkpoint, and it is resumed after the reset.
+			return foundTweets, err
+		}
 		if err != nil {
-			return fmt.Errorf("twitter search error: %w", err)
+			return 0, fmt.Errorf("twitter search error: %w", err)
 		}
+		tweets := output.Tweets
+		run.PagesFetched++
 		// MaxID option includes itself
-		if input.MaxID != 0 && len(tweets) > 0 {
+		if input.MaxID != 0 && len(tweets) > 0 && tweets[0].TweetID == input.MaxID {
 			tweets = tweets[1:]
 		}
 
 		if len(tweets) == 0 {
 			break
 		}
+		foundTweets += len(tweets)
+		run.TweetsSeen += len(tweets)
 
-		for i := 0; i < len(tweets); i++ {
-			tweet := &tweets[i]
-
-			if shouldDetectScore(tweet) {
-				tweetsBuf = append(tweetsBuf, tweet)
-				if len(tweetsBuf) == 25 {
-					err = u.batchStoreTweetsWithDetection(ctx, search, tweetsBuf)
-					if err != nil {
-						return fmt.Errorf("failed to batch store tweets with sentiment detection: %w", err)
-					}
-					tweetsBuf = tweetsBuf[:0]
-				}
+		if checkpoint == nil {
+			checkpoint = &model.CollectionCheckpoint{
+				SinceTweetID: model.TweetID(input.SinceID),
+				UntilTweetID: model.TweetID(newestTweetID(tweets)),
 			}
+			search.CollectionCheckpoint = checkpoint
+		}
+
+		stored, processed, err := u.storeTweets(ctx, search, filter, tweets, maxStoredTweets-storedTweets)
+		if err != nil {
+			return 0, err
+		}
+		storedTweets += stored
+		run.TweetsStored += stored
+		run.TweetsSkipped += processed - int(stored)
+
+		checkpoint.MaxTweetID = model.TweetID(tweets[processed-1].TweetID)
+		checkpoint.UpdatedAt = time.Now()
+		err = u.searchRepository.UpdateCollectionCursor(ctx, search)
+		if errors.Is(err, repository.ErrConflict) {
+			log.Printf("Query of search (id: %s) was changed, collection stopped.\n", search.SearchID)
+			run.AddNote("stopped because the query was changed")
+			return foundTweets, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to save collection checkpoint: %w", err)
+		}
+
+		input.MaxID = int64(checkpoint.MaxTweetID)
+
+		if output.RateLimit.Exhausted() {
+			// The next page would fail until the reset, so the search is postponed without sending it.
+			return foundTweets, output.RateLimit.Err()
+		}
+	}
+
+	if checkpoint != nil {
+		search.SinceTweetID = &checkpoint.UntilTweetID
+		search.CollectionCheckpoint = nil
+		err := u.searchRepository.UpdateCollectionCursor(ctx, search)
+		if err != nil && !errors.Is(err, repository.ErrConflict) {
+			return 0, fmt.Errorf("failed to save collection cursor: %w", err)
+		}
+	}
+
+	return foundTweets, nil
+}
+
+func newestTweetID(tweets []twitter.Tweet) int64 {
+	var id int64
+	for _, tweet := range tweets {
+		if tweet.TweetID > id {
+			id = tweet.TweetID
 		}
+	}
+	return id
+}
+
+// tweetFilter applies the filter rules of a search, and counts tweets dropped by each rule.
+type tweetFilter struct {
+	rules   *model.FilterRules
+	dropped map[model.FilterRuleName]int
+}
+
+// newTweetFilter creates tweetFilter which applies the global rules and the rules of the search.
+func (u *batchUsecase) newTweetFilter(ctx context.Context, search *model.Search) (*tweetFilter, error) {
+	rules, err := findGlobalFilterRules(ctx, u.filterRulesRepository)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tweetFilter{
+		rules:   rules.Merge(search.FilterRules),
+		dropped: map[model.FilterRuleName]int{},
+	}, nil
+}
 
-		input.MaxID = tweets[len(tweets)-1].TweetID
+// accept reports whether the tweet passes all rules. Tweets which are not accepted are not detected nor stored.
+func (f *tweetFilter) accept(tweet *twitter.Tweet) bool {
+	rule := f.rules.Apply(tweet)
+	if rule == "" {
+		return true
 	}
+	f.dropped[rule]++
+	return false
+}
 
-	if len(tweetsBuf) > 0 {
-		err := u.batchStoreTweetsWithDetection(ctx, search, tweetsBuf)
+// storeTweets stores the tweets which are accepted by the filter, up to maxStoredTweets.
+// It returns the number of newly stored tweets, and the number of processed tweets from the head of the given tweets,
+// which is less than len(tweets) if the tweets were not stored entirely because of maxStoredTweets.
+func (u *batchUsecase) storeTweets(ctx context.Context, search *model.Search, filter *tweetFilter, tweets []twitter.Tweet, maxStoredTweets int64) (int64, int, error) {
+	targets := []*twitter.Tweet{}
+	processed := 0
+	for ; processed < len(tweets); processed++ {
+		if int64(len(targets)) == maxStoredTweets {
+			break
+		}
+		if !filter.accept(&tweets[processed]) {
+			continue
+		}
+		targets = append(targets, &tweets[processed])
+	}
+
+	var stored int64
+	for start := 0; start < len(targets); start += 25 {
+		end := start + 25
+		if end > len(targets) {
+			end = len(targets)
+		}
+		n, err := u.batchStoreTweetsWithDetection(ctx, search, targets[start:end])
 		if err != nil {
-			return fmt.Errorf("failed to batch store tweets with sentiment detection: %w", err)
+			return 0, 0, fmt.Errorf("failed to batch store tweets with sentiment detection: %w", err)
 		}
+		stored += n
 	}
 
-	return nil
+	return stored, processed, nil
 }
 
-func (u *batchUsecase) batchStoreTweetsWithDetection(ctx context.Context, search *model.Search, tweets []*twitter.Tweet) error {
+// batchStoreTweetsWithDetection stores the tweets with their sentiments, and returns the number of newly stored tweets.
+// Only newly stored tweets are counted in the usage, because tweets of a retried window may be already stored.
+func (u *batchUsecase) batchStoreTweetsWithDetection(ctx context.Context, search *model.Search, tweets []*twitter.Tweet) (int64, error) {
 	log.Printf("Writing %d tweets with sentiment detection.\n", len(tweets))
 
+	// Detectors detect normalized texts, and they are stored alongside the original texts.
+	normalizedTexts := make([]string, len(tweets))
 	textList := []*string{}
-	for _, tweet := range tweets {
-		textList = append(textList, &tweet.Text)
+	for i, tweet := range tweets {
+		normalizedTexts[i] = u.textPreprocessor.Preprocess(tweet.Text, tweet.Entities)
+		textList = append(textList, &normalizedTexts[i])
+	}
+
+	detector, err := u.sentimentDetectors.Detector(search.SentimentDetector)
+	if err != nil {
+		return 0, fmt.Errorf("failed to select sentiment detector: %w", err)
 	}
 
-	detectOutputs, err := u.sentimentDetector.BatchDetect(ctx, textList)
+	detectOutputs, err := batchDetectTexts(ctx, detector, textList)
 	if err != nil {
-		return fmt.Errorf("failed to batch detect sentiment score %w", err)
+		return 0, fmt.Errorf("failed to batch detect sentiment score %w", err)
 	}
 
 	modelTweets := []*model.Tweet{}
 
 	for i, tweet := range tweets {
 		detectOutput := detectOutputs[i]
+		// Tweets whose detection failed are stored with LabelUnknown, and the others of the batch are not affected.
+		score := &detectOutput.Score
+		detectorInfo := &detectOutputs[i].Detector
+		if normalizedTexts[i] == "" {
+			// Empty texts are not detected, see batchDetectTexts.
+			score, detectorInfo = nil, nil
+		} else if detectOutput.Err != nil {
+			log.Printf("Failed to detect sentiment of tweet (id: %d): %s\n", tweet.TweetID, detectOutput.Err)
+			score = nil
+		}
 
 		modelTweets = append(modelTweets, &model.Tweet{
 			TweetID:  model.TweetID(tweet.TweetID),
@@ -167,45 +551,69 @@ func (u *batchUsecase) batchStoreTweetsWithDetection(ctx context.Context, search
 				ScreenName:      tweet.User.ScreenName,
 				ProfileImageURL: tweet.User.ProfileImageURL,
 			},
-			Entities:           tweet.Entities,
-			Text:               tweet.Text,
-			SentimentScore:     &detectOutput.Score,
-			SentimentLabel:     detectOutput.Label,
-			ExpirationUnixTime: time.Now().AddDate(0, 6, 0).Unix(),
-			TweetCreatedAt:     tweet.CreatedAt,
+			Entities:            tweet.Entities,
+			Text:                tweet.Text,
+			NormalizedText:      normalizedTexts[i],
+			SentimentScore:      score,
+			SentimentLabel:      detectOutput.Label,
+			SentimentDetector:   detectorInfo,
+			SentimentConfidence: detectOutput.Confidence,
+			Emotions:            detectOutput.Emotions,
+			DominantEmotion:     dominantEmotion(detectOutput.Emotions),
+			ExpirationUnixTime:  time.Now().AddDate(0, 6, 0).Unix(),
+			TweetCreatedAt:      tweet.CreatedAt,
 		})
 	}
 
-	err = u.tweetRepository.BatchStore(ctx, modelTweets)
+	stored, err := u.tweetRepository.BatchStore(ctx, modelTweets)
+	if stored > 0 {
+		// Tweets stored before a failure are counted, because they are not stored again by a retry.
+		usageErr := u.quota.userUsageRepository.AddStoredTweets(ctx, search.UserID, time.Now(), int64(stored))
+		if err == nil && usageErr != nil {
+			return 0, fmt.Errorf("failed to count stored tweets: %w", usageErr)
+		}
+	}
 	if err != nil {
-		return fmt.Errorf("failed to batch store tweets: %w", err)
+		return 0, fmt.Errorf("failed to batch store tweets: %w", err)
 	}
 
-	return nil
+	return int64(stored), nil
 }
 
-func shouldDetectScore(tweet *twitter.Tweet) bool {
-	ngURLs := []string{"youtu.be", "youtube.com", "nicovideo", "nico.ms", "peing.net"}
-
-	for _, url := range tweet.Entities.URLs {
-		for _, ngURL := range ngURLs {
-			if strings.Contains(url.ExpandedURL, ngURL) {
-				return false
-			}
+// batchDetectTexts detects sentiments of the texts by the detector except empty texts,
+// e.g. tweets of only URLs and mentions, which have nothing to detect.
+// Outputs of empty texts have LabelUnknown without scores and detectors.
+func batchDetectTexts(ctx context.Context, detector sentiment.Detector, textList []*string) ([]sentiment.DetectOutput, error) {
+	outputs := make([]sentiment.DetectOutput, len(textList))
+	indexes := []int{}
+	targets := []*string{}
+	for i, text := range textList {
+		if *text == "" {
+			outputs[i] = sentiment.DetectOutput{Label: sentiment.LabelUnknown}
+			continue
 		}
+		indexes = append(indexes, i)
+		targets = append(targets, text)
 	}
-
-	textLen := len(tweet.Text)
-
-	for _, url := range tweet.Entities.URLs {
-		textLen -= len(url.URL)
+	if len(targets) == 0 {
+		return outputs, nil
 	}
-	for _, men := range tweet.Entities.Mentions {
-		textLen -= len(men.Tag)
+
+	detected, err := detector.BatchDetect(ctx, targets)
+	if err != nil {
+		return nil, err
 	}
-	for _, hash := range tweet.Entities.HashTags {
-		textLen -= len(hash.Tag)
+	for j, i := range indexes {
+		outputs[i] = detected[j]
 	}
 
-	return textLen >= 160
+	return outputs, nil
+}
+
+// dominantEmotion returns the dominant emotion of the emotions, or the empty emotion if they were not detected.
+func dominantEmotion(emotions *sentiment.EmotionScore) sentiment.Emotion {
+	if emotions == nil {
+		return ""
+	}
+	return emotions.Dominant()
 }
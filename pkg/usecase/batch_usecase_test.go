@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
+)
+
+// positiveDetector detects every text as positive, and records the detected texts.
+type positiveDetector struct {
+	texts []string
+}
+
+func (d *positiveDetector) BatchDetect(ctx context.Context, textList []*string) ([]sentiment.DetectOutput, error) {
+	outputs := []sentiment.DetectOutput{}
+	for _, text := range textList {
+		d.texts = append(d.texts, *text)
+		positive, negative, neutral := 0.8, 0.1, 0.1
+		outputs = append(outputs, sentiment.DetectOutput{
+			Score:    sentiment.Score{Positive: &positive, Negative: &negative, Neutral: &neutral},
+			Label:    sentiment.LabelPositive,
+			Detector: sentiment.DetectorInfo{Name: sentiment.DetectorBayes},
+		})
+	}
+	return outputs, nil
+}
+
+func Test_batchDetectTexts(t *testing.T) {
+	texts := []string{"楽しい", "", "嬉しい"}
+	detector := &positiveDetector{}
+
+	outputs, err := batchDetectTexts(context.Background(), detector, []*string{&texts[0], &texts[1], &texts[2]})
+	if err != nil {
+		t.Fatalf("batchDetectTexts returned error: %s", err)
+	}
+
+	if len(detector.texts) != 2 || detector.texts[0] != "楽しい" || detector.texts[1] != "嬉しい" {
+		t.Errorf("detected texts = %q, want only the non-empty texts", detector.texts)
+	}
+	wantLabels := []sentiment.Label{sentiment.LabelPositive, sentiment.LabelUnknown, sentiment.LabelPositive}
+	if len(outputs) != len(wantLabels) {
+		t.Fatalf("batchDetectTexts returned %d outputs, want %d", len(outputs), len(wantLabels))
+	}
+	for i, want := range wantLabels {
+		if outputs[i].Label != want {
+			t.Errorf("label of output %d = %s, want %s", i, outputs[i].Label, want)
+		}
+	}
+	if outputs[1].Score.Positive != nil || outputs[1].Detector.Name != "" {
+		t.Errorf("output of the empty text = %+v, want no score and detector", outputs[1])
+	}
+
+	// Batches of only empty texts are not sent to the detector.
+	detector = &positiveDetector{}
+	if _, err := batchDetectTexts(context.Background(), detector, []*string{&texts[1]}); err != nil || len(detector.texts) != 0 {
+		t.Errorf("batchDetectTexts detected %q with error %v, want no detection", detector.texts, err)
+	}
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hareku/emosearch-api/pkg/domain/auth"
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+	"github.com/hareku/emosearch-api/pkg/domain/repository"
+	"github.com/hareku/emosearch-api/pkg/domain/validator"
+)
+
+// FilterRulesUsecase provides usecases of the global filter rules which are applied to all searches.
+type FilterRulesUsecase interface {
+	GetGlobalFilterRules(ctx context.Context) (*model.FilterRules, error)
+	UpdateGlobalFilterRules(ctx context.Context, input *FilterRulesInput) (*model.FilterRules, error)
+}
+
+type filterRulesUsecase struct {
+	authenticator         auth.Authenticator
+	validator             validator.Validator
+	filterRulesRepository repository.FilterRulesRepository
+}
+
+// NewFilterRulesUsecase creates FilterRulesUsecase.
+func NewFilterRulesUsecase(authenticator auth.Authenticator, validator validator.Validator, filterRulesRepository repository.FilterRulesRepository) FilterRulesUsecase {
+	return &filterRulesUsecase{authenticator, validator, filterRulesRepository}
+}
+
+// FilterRulesInput is the input of filter rules of a search or the global ones.
+type FilterRulesInput struct {
+	BlockedDomains    []string `json:"BlockedDomains" validate:"lte=100,dive,required,lte=253"`
+	BlockedAuthors    []string `json:"BlockedAuthors" validate:"lte=100,dive,required,lte=16"`
+	RequiredKeywords  []string `json:"RequiredKeywords" validate:"lte=10,dive,required,lte=100"`
+	ForbiddenKeywords []string `json:"ForbiddenKeywords" validate:"lte=100,dive,required,lte=100"`
+	MinTextLength     *int     `json:"MinTextLength" validate:"omitempty,gte=0,lte=280"`
+	ExcludeMediaOnly  bool     `json:"ExcludeMediaOnly"`
+}
+
+// toModel returns the rules whose values are normalized.
+func (i *FilterRulesInput) toModel() *model.FilterRules {
+	return &model.FilterRules{
+		BlockedDomains:    normalizeFilterValues(i.BlockedDomains, "."),
+		BlockedAuthors:    normalizeFilterValues(i.BlockedAuthors, "@"),
+		RequiredKeywords:  normalizeFilterValues(i.RequiredKeywords, ""),
+		ForbiddenKeywords: normalizeFilterValues(i.ForbiddenKeywords, ""),
+		MinTextLength:     i.MinTextLength,
+		ExcludeMediaOnly:  i.ExcludeMediaOnly,
+	}
+}
+
+// normalizeFilterValues trims spaces and the prefix of values, and removes empty and duplicated ones.
+func normalizeFilterValues(values []string, prefix string) []string {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, v := range values {
+		v = strings.TrimPrefix(strings.TrimSpace(v), prefix)
+		key := strings.ToLower(v)
+		if v == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, v)
+	}
+	return normalized
+}
+
+// GetGlobalFilterRules returns the global filter rules. Only admins can call it.
+func (u *filterRulesUsecase) GetGlobalFilterRules(ctx context.Context) (*model.FilterRules, error) {
+	if !u.authenticator.IsAdmin(ctx) {
+		return nil, ErrNotAdmin
+	}
+
+	return findGlobalFilterRules(ctx, u.filterRulesRepository)
+}
+
+// UpdateGlobalFilterRules replaces the global filter rules. Only admins can call it.
+// Tweets which have been collected already are not affected.
+func (u *filterRulesUsecase) UpdateGlobalFilterRules(ctx context.Context, input *FilterRulesInput) (*model.FilterRules, error) {
+	if !u.authenticator.IsAdmin(ctx) {
+		return nil, ErrNotAdmin
+	}
+
+	err := u.validator.StructCtx(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := input.toModel()
+	err = u.filterRulesRepository.UpdateGlobal(ctx, rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update global filter rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// findGlobalFilterRules returns the global filter rules, or the default ones if admins have never configured them.
+func findGlobalFilterRules(ctx context.Context, filterRulesRepository repository.FilterRulesRepository) (*model.FilterRules, error) {
+	rules, err := filterRulesRepository.FindGlobal(ctx)
+	if errors.Is(err, repository.ErrNotFound) {
+		return model.DefaultFilterRules(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch global filter rules: %w", err)
+	}
+	return rules, nil
+}
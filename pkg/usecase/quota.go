@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+	"github.com/hareku/emosearch-api/pkg/domain/repository"
+)
+
+// QuotaExceededError is returned when an operation exceeds the quota of the user.
+type QuotaExceededError struct {
+	Quota   model.QuotaName
+	Limit   int64
+	Current int64
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("quota exceeded: %s (limit: %d, current: %d)", e.Quota, e.Limit, e.Current)
+}
+
+// quotaChecker checks quotas of users.
+type quotaChecker struct {
+	userRepository      repository.UserRepository
+	userUsageRepository repository.UserUsageRepository
+	searchRepository    repository.SearchRepository
+}
+
+func (c *quotaChecker) findQuota(ctx context.Context, userID model.UserID) (model.Quota, error) {
+	user, err := c.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return model.Quota{}, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	return user.CurrentQuota(), nil
+}
+
+// checkActiveSearches checks whether the user can have one more active search.
+func (c *quotaChecker) checkActiveSearches(ctx context.Context, userID model.UserID, quota model.Quota) error {
+	searches, err := c.searchRepository.ListByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("failed to fetch searches: %w", err)
+	}
+
+	active := 0
+	for _, search := range searches {
+		if search.IsActive() {
+			active++
+		}
+	}
+
+	if active >= quota.MaxActiveSearches {
+		return &QuotaExceededError{
+			Quota:   model.QuotaMaxActiveSearches,
+			Limit:   int64(quota.MaxActiveSearches),
+			Current: int64(active),
+		}
+	}
+	return nil
+}
+
+// checkInterval checks whether the collection interval is allowed.
+func (c *quotaChecker) checkInterval(intervalMinutes int, quota model.Quota) error {
+	if intervalMinutes < quota.MinIntervalMinutes {
+		return &QuotaExceededError{
+			Quota:   model.QuotaMinIntervalMinutes,
+			Limit:   int64(quota.MinIntervalMinutes),
+			Current: int64(intervalMinutes),
+		}
+	}
+	return nil
+}
+
+// remainingTweets returns the number of tweets which the user can store in this month.
+func (c *quotaChecker) remainingTweets(ctx context.Context, userID model.UserID, quota model.Quota) (int64, error) {
+	usage, err := c.userUsageRepository.FindMonthly(ctx, userID, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch user usage: %w", err)
+	}
+
+	remaining := quota.MaxTweetsPerMonth - usage.StoredTweetCount
+	if remaining < 0 {
+		return 0, nil
+	}
+	return remaining, nil
+}
+
+// checkMonthlyTweets checks whether the user can store more tweets in this month.
+func (c *quotaChecker) checkMonthlyTweets(ctx context.Context, userID model.UserID, quota model.Quota) error {
+	usage, err := c.userUsageRepository.FindMonthly(ctx, userID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to fetch user usage: %w", err)
+	}
+
+	if usage.StoredTweetCount >= quota.MaxTweetsPerMonth {
+		return &QuotaExceededError{
+			Quota:   model.QuotaMaxTweetsPerMonth,
+			Limit:   quota.MaxTweetsPerMonth,
+			Current: usage.StoredTweetCount,
+		}
+	}
+	return nil
+}
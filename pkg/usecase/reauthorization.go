@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+	"github.com/hareku/emosearch-api/pkg/domain/repository"
+)
+
+// reauthorizer pauses searches of users whose Twitter tokens were revoked, and resumes them when new tokens are registered.
+type reauthorizer struct {
+	userRepository   repository.UserRepository
+	searchRepository repository.SearchRepository
+}
+
+// requireReauthorization marks the user as needing reauthorization, and pauses the active searches of the user.
+func (r *reauthorizer) requireReauthorization(ctx context.Context, user *model.User) error {
+	if !user.NeedsReauthorization() {
+		now := time.Now()
+		user.ReauthorizationRequiredAt = &now
+		err := r.userRepository.UpdateAuthorization(ctx, user)
+		if err != nil {
+			return fmt.Errorf("failed to mark user as needing reauthorization: %w", err)
+		}
+	}
+
+	searches, err := r.searchRepository.ListByUserID(ctx, user.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to list searches of user (id: %s): %w", user.UserID, err)
+	}
+	for _, search := range searches {
+		if !search.IsActive() {
+			continue
+		}
+
+		search.NextSearchUpdateReason = "paused until the twitter token is reauthorized"
+		err := r.searchRepository.UpdateStatus(ctx, search, model.SearchStatusPaused, model.SearchPauseReasonReauthorization)
+		if errors.Is(err, repository.ErrConflict) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to pause search (id: %s): %w", search.SearchID, err)
+		}
+	}
+
+	return nil
+}
+
+// reauthorize saves the new token of the user, and resumes the searches which were paused by requireReauthorization.
+func (r *reauthorizer) reauthorize(ctx context.Context, user *model.User, accessToken string, accessTokenSecret string) error {
+	user.TwitterAccessToken = accessToken
+	user.TwitterAccessTokenSecret = accessTokenSecret
+	user.ReauthorizationRequiredAt = nil
+	err := r.userRepository.UpdateAuthorization(ctx, user)
+	if err != nil {
+		return fmt.Errorf("failed to update twitter token: %w", err)
+	}
+
+	searches, err := r.searchRepository.ListByUserID(ctx, user.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to list searches of user (id: %s): %w", user.UserID, err)
+	}
+	now := time.Now()
+	for _, search := range searches {
+		if search.Status != model.SearchStatusPaused || search.PauseReason != model.SearchPauseReasonReauthorization {
+			continue
+		}
+
+		search.NextSearchUpdateAt = now
+		search.NextSearchUpdateReason = "twitter token was reauthorized"
+		err := r.searchRepository.UpdateStatus(ctx, search, model.SearchStatusActive, "")
+		if errors.Is(err, repository.ErrConflict) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to resume search (id: %s): %w", search.SearchID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+)
+
+const (
+	// busySearchTweets is the number of tweets found in a collection, which shortens the adaptive interval.
+	busySearchTweets = 500
+
+	// quietSearchTweets is the number of tweets found in a collection, which lengthens the adaptive interval.
+	quietSearchTweets = 10
+)
+
+// scheduleNextUpdate decides the next collection of the search, which found the given number of tweets in the collection started at lastUpdatedAt.
+// The interval is not shorter than minInterval.
+func scheduleNextUpdate(search *model.Search, lastUpdatedAt time.Time, foundTweets int, minInterval time.Duration) {
+	interval := search.EffectiveInterval()
+	reason := fmt.Sprintf("interval of %d minutes", interval/time.Minute)
+
+	if search.AdaptiveInterval {
+		switch {
+		case foundTweets >= busySearchTweets:
+			interval = clampInterval(search, interval/2)
+			reason = fmt.Sprintf("adaptive: %d tweets were found, interval is shortened to %d minutes", foundTweets, interval/time.Minute)
+		case foundTweets <= quietSearchTweets:
+			interval = clampInterval(search, interval*2)
+			reason = fmt.Sprintf("adaptive: %d tweets were found, interval is lengthened to %d minutes", foundTweets, interval/time.Minute)
+		default:
+			reason = fmt.Sprintf("adaptive: %d tweets were found, interval is kept at %d minutes", foundTweets, interval/time.Minute)
+		}
+		search.EffectiveIntervalMinutes = int(interval / time.Minute)
+	}
+
+	if interval < minInterval {
+		interval = minInterval
+		reason = fmt.Sprintf("interval is limited to %d minutes by the quota", interval/time.Minute)
+	}
+
+	search.LastSearchUpdatedAt = &lastUpdatedAt
+	search.NextSearchUpdateAt = lastUpdatedAt.Add(interval)
+	search.NextSearchUpdateReason = reason
+}
+
+// clampInterval limits the adaptive interval to the range around the interval configured by the user.
+func clampInterval(search *model.Search, interval time.Duration) time.Duration {
+	min, max := search.AdaptiveIntervalRange()
+	if interval < min {
+		return min
+	}
+	if interval > max {
+		return max
+	}
+	return interval
+}
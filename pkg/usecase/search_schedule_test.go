@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+)
+
+func Test_scheduleNextUpdate(t *testing.T) {
+	lastUpdatedAt := time.Date(2021, 1, 2, 3, 4, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		search        model.Search
+		foundTweets   int
+		minInterval   time.Duration
+		wantInterval  time.Duration
+		wantEffective int
+	}{
+		{
+			name:         "not adaptive",
+			search:       model.Search{IntervalMinutes: 60},
+			foundTweets:  1000,
+			wantInterval: 60 * time.Minute,
+		},
+		{
+			name:         "default interval",
+			search:       model.Search{},
+			foundTweets:  0,
+			wantInterval: model.DefaultSearchIntervalMinutes * time.Minute,
+		},
+		{
+			name:          "busy search halves the interval",
+			search:        model.Search{IntervalMinutes: 60, AdaptiveInterval: true},
+			foundTweets:   busySearchTweets,
+			wantInterval:  30 * time.Minute,
+			wantEffective: 30,
+		},
+		{
+			name:          "quiet search doubles the adjusted interval",
+			search:        model.Search{IntervalMinutes: 60, AdaptiveInterval: true, EffectiveIntervalMinutes: 120},
+			foundTweets:   quietSearchTweets,
+			wantInterval:  240 * time.Minute,
+			wantEffective: 240,
+		},
+		{
+			name:          "interval is kept between busy and quiet",
+			search:        model.Search{IntervalMinutes: 60, AdaptiveInterval: true, EffectiveIntervalMinutes: 120},
+			foundTweets:   quietSearchTweets + 1,
+			wantInterval:  120 * time.Minute,
+			wantEffective: 120,
+		},
+		{
+			name:          "halved interval is clamped to the minimum",
+			search:        model.Search{IntervalMinutes: 8, AdaptiveInterval: true},
+			foundTweets:   busySearchTweets,
+			wantInterval:  model.MinSearchIntervalMinutes * time.Minute,
+			wantEffective: model.MinSearchIntervalMinutes,
+		},
+		{
+			name:          "halved interval is clamped relative to the configured interval",
+			search:        model.Search{IntervalMinutes: 120, AdaptiveInterval: true, EffectiveIntervalMinutes: 40},
+			foundTweets:   busySearchTweets,
+			wantInterval:  30 * time.Minute,
+			wantEffective: 30,
+		},
+		{
+			name:          "doubled interval is clamped relative to the configured interval",
+			search:        model.Search{IntervalMinutes: 60, AdaptiveInterval: true, EffectiveIntervalMinutes: 180},
+			foundTweets:   0,
+			wantInterval:  240 * time.Minute,
+			wantEffective: 240,
+		},
+		{
+			name:          "doubled interval is clamped to the maximum",
+			search:        model.Search{IntervalMinutes: 720, AdaptiveInterval: true, EffectiveIntervalMinutes: 1000},
+			foundTweets:   0,
+			wantInterval:  model.MaxSearchIntervalMinutes * time.Minute,
+			wantEffective: model.MaxSearchIntervalMinutes,
+		},
+		{
+			name:          "quota limits the interval but not the adjusted one",
+			search:        model.Search{IntervalMinutes: 60, AdaptiveInterval: true},
+			foundTweets:   busySearchTweets,
+			minInterval:   45 * time.Minute,
+			wantInterval:  45 * time.Minute,
+			wantEffective: 30,
+		},
+	}
+
+	for _, tt := range tests {
+		search := tt.search
+		scheduleNextUpdate(&search, lastUpdatedAt, tt.foundTweets, tt.minInterval)
+
+		if search.LastSearchUpdatedAt == nil || !search.LastSearchUpdatedAt.Equal(lastUpdatedAt) {
+			t.Errorf("%s: LastSearchUpdatedAt = %v, want %v", tt.name, search.LastSearchUpdatedAt, lastUpdatedAt)
+		}
+		if got := search.NextSearchUpdateAt.Sub(lastUpdatedAt); got != tt.wantInterval {
+			t.Errorf("%s: interval = %v, want %v", tt.name, got, tt.wantInterval)
+		}
+		if search.EffectiveIntervalMinutes != tt.wantEffective {
+			t.Errorf("%s: EffectiveIntervalMinutes = %d, want %d", tt.name, search.EffectiveIntervalMinutes, tt.wantEffective)
+		}
+		if search.NextSearchUpdateReason == "" {
+			t.Errorf("%s: NextSearchUpdateReason is empty", tt.name)
+		}
+	}
+}
+
+func Test_scheduleNextUpdate_Backoff(t *testing.T) {
+	search := &model.Search{IntervalMinutes: 10, AdaptiveInterval: true}
+	lastUpdatedAt := time.Date(2021, 1, 2, 3, 4, 0, 0, time.UTC)
+
+	// Repeated quiet collections back off until 4 times of the configured interval.
+	want := []int{20, 40, 40}
+	for i, w := range want {
+		scheduleNextUpdate(search, lastUpdatedAt, 0, 0)
+		if search.EffectiveIntervalMinutes != w {
+			t.Errorf("collection %d: EffectiveIntervalMinutes = %d, want %d", i+1, search.EffectiveIntervalMinutes, w)
+		}
+		lastUpdatedAt = search.NextSearchUpdateAt
+	}
+
+	// A busy collection shortens it again.
+	scheduleNextUpdate(search, lastUpdatedAt, busySearchTweets, 0)
+	if search.EffectiveIntervalMinutes != 20 {
+		t.Errorf("EffectiveIntervalMinutes = %d, want 20", search.EffectiveIntervalMinutes)
+	}
+}
@@ -4,14 +4,23 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/hareku/emosearch-api/pkg/domain/auth"
+	"github.com/hareku/emosearch-api/pkg/domain/job"
 	"github.com/hareku/emosearch-api/pkg/domain/model"
 	"github.com/hareku/emosearch-api/pkg/domain/repository"
+	"github.com/hareku/emosearch-api/pkg/domain/sentiment"
+	"github.com/hareku/emosearch-api/pkg/domain/twitter"
 	"github.com/hareku/emosearch-api/pkg/domain/validator"
 )
 
+var (
+	// ErrSearchDeleting is returned when the search is being deleted.
+	ErrSearchDeleting = errors.New("search is being deleted")
+)
+
 // SearchUsecase provides usecases of Search domain.
 type SearchUsecase interface {
 	ListShouldUpdateSearches(ctx context.Context) ([]*model.Search, error)
@@ -19,20 +28,58 @@ type SearchUsecase interface {
 	ListUserSearches(ctx context.Context) ([]*model.Search, error)
 	Find(ctx context.Context, searchID model.SearchID, userID model.UserID) (*model.Search, error)
 	GetUserSearch(ctx context.Context, searchID model.SearchID) (*model.Search, error)
-	DeleteUserSearch(ctx context.Context, searchID model.SearchID) error
+	GetSearchStats(ctx context.Context, search *model.Search) (*model.SearchStats, error)
+	ListUserSearchRuns(ctx context.Context, searchID model.SearchID, limit int64) ([]*model.CollectionRun, error)
+	ListUserSearchRescorings(ctx context.Context, searchID model.SearchID, limit int64) ([]*model.TweetRescoring, error)
+	DeleteUserSearch(ctx context.Context, searchID model.SearchID) (*model.Search, error)
 	Create(ctx context.Context, input *SearchUsecaseCreateInput) (*model.Search, error)
+	UpdateUserSearch(ctx context.Context, input *SearchUsecaseUpdateInput) (*model.Search, error)
+	ChangeUserSearchStatus(ctx context.Context, searchID model.SearchID, status model.SearchStatus) (*model.Search, error)
 	UpdateNextUpdateAt(ctx context.Context, search *model.Search) error
+	ScheduleNextUpdate(ctx context.Context, search *model.Search, quota model.Quota, foundTweets int) error
+	PostponeUserSearches(ctx context.Context, userID model.UserID, until time.Time, reason string) error
 }
 
 type searchUsecase struct {
-	authenticator    auth.Authenticator
-	validator        validator.Validator
-	searchRepository repository.SearchRepository
+	authenticator            auth.Authenticator
+	validator                validator.Validator
+	searchRepository         repository.SearchRepository
+	searchStatsRepository    repository.SearchStatsRepository
+	collectionRunRepository  repository.CollectionRunRepository
+	tweetRescoringRepository repository.TweetRescoringRepository
+	jobDispatcher            job.Dispatcher
+	quota                    *quotaChecker
+}
+
+// NewSearchUsecaseInput is the input of NewSearchUsecase.
+type NewSearchUsecaseInput struct {
+	Authenticator            auth.Authenticator
+	Validator                validator.Validator
+	SearchRepository         repository.SearchRepository
+	SearchStatsRepository    repository.SearchStatsRepository
+	CollectionRunRepository  repository.CollectionRunRepository
+	TweetRescoringRepository repository.TweetRescoringRepository
+	UserRepository           repository.UserRepository
+	UserUsageRepository      repository.UserUsageRepository
+	JobDispatcher            job.Dispatcher
 }
 
 // NewSearchUsecase creates SearchUsecase.
-func NewSearchUsecase(authenticator auth.Authenticator, validator validator.Validator, searchRepository repository.SearchRepository) SearchUsecase {
-	return &searchUsecase{authenticator, validator, searchRepository}
+func NewSearchUsecase(input *NewSearchUsecaseInput) SearchUsecase {
+	return &searchUsecase{
+		authenticator:            input.Authenticator,
+		validator:                input.Validator,
+		searchRepository:         input.SearchRepository,
+		searchStatsRepository:    input.SearchStatsRepository,
+		collectionRunRepository:  input.CollectionRunRepository,
+		tweetRescoringRepository: input.TweetRescoringRepository,
+		jobDispatcher:            input.JobDispatcher,
+		quota: &quotaChecker{
+			userRepository:      input.UserRepository,
+			userUsageRepository: input.UserUsageRepository,
+			searchRepository:    input.SearchRepository,
+		},
+	}
 }
 
 func (u *searchUsecase) ListShouldUpdateSearches(ctx context.Context) ([]*model.Search, error) {
@@ -45,7 +92,15 @@ func (u *searchUsecase) ListShouldUpdateSearches(ctx context.Context) ([]*model.
 		return nil, fmt.Errorf("failed to list searches: %w", err)
 	}
 
-	return searches, nil
+	// The index is eventually consistent, so searches which were paused just now may be listed.
+	res := []*model.Search{}
+	for _, search := range searches {
+		if search.IsActive() {
+			res = append(res, search)
+		}
+	}
+
+	return res, nil
 }
 
 func (u *searchUsecase) ListByUserID(ctx context.Context, userID model.UserID) ([]*model.Search, error) {
@@ -82,25 +137,90 @@ func (u *searchUsecase) GetUserSearch(ctx context.Context, searchID model.Search
 	return search, nil
 }
 
-func (u *searchUsecase) DeleteUserSearch(ctx context.Context, searchID model.SearchID) error {
+func (u *searchUsecase) GetSearchStats(ctx context.Context, search *model.Search) (*model.SearchStats, error) {
+	stats, err := u.searchStatsRepository.Find(ctx, search.SearchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch search stats (id: %v): %w", search.SearchID, err)
+	}
+	return stats, nil
+}
+
+// ListUserSearchRuns returns the latest collection runs of the search of the authenticated user.
+func (u *searchUsecase) ListUserSearchRuns(ctx context.Context, searchID model.SearchID, limit int64) ([]*model.CollectionRun, error) {
+	userID, err := u.authenticator.UserID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user id: %w", err)
+	}
+
+	_, err = u.searchRepository.Find(ctx, userID, searchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch search (id: %v): %w", searchID, err)
+	}
+
+	runs, err := u.collectionRunRepository.ListBySearchID(ctx, searchID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch collection runs (id: %v): %w", searchID, err)
+	}
+	return runs, nil
+}
+
+// ListUserSearchRescorings returns the reports of the latest re-scorings of the search of the authenticated user.
+func (u *searchUsecase) ListUserSearchRescorings(ctx context.Context, searchID model.SearchID, limit int64) ([]*model.TweetRescoring, error) {
 	userID, err := u.authenticator.UserID(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to fetch user id: %w", err)
+		return nil, fmt.Errorf("failed to fetch user id: %w", err)
+	}
+
+	_, err = u.searchRepository.Find(ctx, userID, searchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch search (id: %v): %w", searchID, err)
+	}
+
+	rescorings, err := u.tweetRescoringRepository.ListBySearchID(ctx, searchID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch re-scorings (id: %v): %w", searchID, err)
+	}
+	return rescorings, nil
+}
+
+// DeleteUserSearch marks the search of the authenticated user as deleting,
+// and dispatches the job which deletes the search and its collected tweets.
+func (u *searchUsecase) DeleteUserSearch(ctx context.Context, searchID model.SearchID) (*model.Search, error) {
+	userID, err := u.authenticator.UserID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user id: %w", err)
 	}
 
 	search, err := u.searchRepository.Find(ctx, userID, searchID)
 	if errors.Is(err, repository.ErrNotFound) {
-		return fmt.Errorf("search was not found (id: %v): %w", searchID, err)
+		return nil, fmt.Errorf("search was not found (id: %v): %w", searchID, err)
 	}
 	if err != nil {
-		return fmt.Errorf("failed to fetch search (id: %v): %w", searchID, err)
+		return nil, fmt.Errorf("failed to fetch search (id: %v): %w", searchID, err)
+	}
+
+	// The job is dispatched again even if the search is already deleting,
+	// so that the deletion can be retried by the user when the previous job failed.
+	if search.Status != model.SearchStatusDeleting {
+		search.Status = model.SearchStatusDeleting
+		search.DeletedTweetCount = 0
+		search.UpdatedAt = time.Now()
+
+		err = u.searchRepository.Update(ctx, search)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("search was already deleted (id: %v): %w", searchID, err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to mark search as deleting (id: %v): %w", searchID, err)
+		}
 	}
 
-	err = u.searchRepository.Delete(ctx, search)
+	err = u.jobDispatcher.DispatchSearchDeletion(ctx, search.SearchID, search.UserID)
 	if err != nil {
-		return fmt.Errorf("failed to delete search (id: %v): %w", searchID, err)
+		return nil, fmt.Errorf("failed to dispatch search deletion (id: %v): %w", searchID, err)
 	}
-	return nil
+
+	return search, nil
 }
 
 func (u *searchUsecase) Find(ctx context.Context, searchID model.SearchID, userID model.UserID) (*model.Search, error) {
@@ -115,8 +235,15 @@ func (u *searchUsecase) Find(ctx context.Context, searchID model.SearchID, userI
 }
 
 // SearchUsecaseCreateInput is the input of SearchUsecase.Create().
+// IntervalMinutes defaults to model.DefaultSearchIntervalMinutes.
 type SearchUsecaseCreateInput struct {
-	Query string `validate:"required,gte=3,lte=100"`
+	Title            string `validate:"lte=100"`
+	Query            string `validate:"required,gte=3,lte=100"`
+	IntervalMinutes  int    `validate:"omitempty,gte=5,lte=1440"`
+	AdaptiveInterval bool
+	// SentimentDetector defaults to the configured default detector.
+	SentimentDetector string `validate:"omitempty,oneof=bayes comprehend lexicon ensemble"`
+	FilterRules       *FilterRulesInput
 }
 
 func (u *searchUsecase) Create(ctx context.Context, input *SearchUsecaseCreateInput) (*model.Search, error) {
@@ -124,20 +251,59 @@ func (u *searchUsecase) Create(ctx context.Context, input *SearchUsecaseCreateIn
 	if err != nil {
 		return nil, err
 	}
+	query, err := normalizeSearchQuery(input.Query)
+	if err != nil {
+		return nil, err
+	}
 
 	userID, err := u.authenticator.UserID(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("fetching user id error: %w", err)
 	}
 
+	intervalMinutes := input.IntervalMinutes
+	if intervalMinutes == 0 {
+		intervalMinutes = model.DefaultSearchIntervalMinutes
+	}
+
+	quota, err := u.quota.findQuota(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err = u.quota.checkInterval(intervalMinutes, quota); err != nil {
+		return nil, err
+	}
+	if err = u.quota.checkActiveSearches(ctx, userID, quota); err != nil {
+		return nil, err
+	}
+	if err = u.quota.checkMonthlyTweets(ctx, userID, quota); err != nil {
+		return nil, err
+	}
+
+	// Tweets posted after the search was created are collected periodically,
+	// and tweets posted before it are collected by the backfill.
+	now := time.Now()
+	sinceTweetID := model.TweetID(twitter.MinTweetIDAt(now))
 	search := &model.Search{
-		UserID:              userID,
-		Title:               "",
-		Query:               input.Query,
-		LastSearchUpdatedAt: nil,
-		NextSearchUpdateAt:  time.Now().AddDate(-1, 0, 0),
-		CreatedAt:           time.Now(),
-		UpdatedAt:           time.Now(),
+		UserID:                   userID,
+		Title:                    input.Title,
+		Query:                    query,
+		Status:                   model.SearchStatusActive,
+		LastSearchUpdatedAt:      nil,
+		NextSearchUpdateAt:       now.AddDate(-1, 0, 0),
+		NextSearchUpdateReason:   "created",
+		IntervalMinutes:          intervalMinutes,
+		AdaptiveInterval:         input.AdaptiveInterval,
+		EffectiveIntervalMinutes: intervalMinutes,
+		SinceTweetID:             &sinceTweetID,
+		SentimentDetector:        sentiment.DetectorName(input.SentimentDetector),
+		Backfill:                 model.NewSearchBackfill(now),
+		CreatedAt:                now,
+		UpdatedAt:                now,
+	}
+
+	if input.FilterRules != nil {
+		search.FilterRules = input.FilterRules.toModel()
 	}
 
 	err = u.searchRepository.Create(ctx, search)
@@ -145,18 +311,256 @@ func (u *searchUsecase) Create(ctx context.Context, input *SearchUsecaseCreateIn
 		return nil, fmt.Errorf("creating search error: %w", err)
 	}
 
+	u.dispatchBackfill(ctx, search)
+
+	return search, nil
+}
+
+// dispatchBackfill starts the backfill job of the search.
+// The search has been saved already, so the failure is only logged, and the job is dispatched again by the collection batch.
+func (u *searchUsecase) dispatchBackfill(ctx context.Context, search *model.Search) {
+	err := u.jobDispatcher.DispatchSearchBackfill(ctx, search.SearchID, search.UserID)
+	if err != nil {
+		log.Printf("Failed to dispatch backfill of search (id: %s): %s\n", search.SearchID, err)
+	}
+}
+
+// normalizeSearchQuery validates the query by the syntax of Twitter standard search, and returns the normalized query.
+func normalizeSearchQuery(query string) (string, error) {
+	q, err := twitter.ParseQuery(query)
+	if err != nil {
+		return "", validator.FieldErrors{"Query": err.Error()}
+	}
+	return q.String(), nil
+}
+
+// SearchUsecaseUpdateInput is the input of SearchUsecase.UpdateUserSearch().
+// Nil fields are not updated, and FilterRules replaces the whole rules of the search.
+type SearchUsecaseUpdateInput struct {
+	SearchID         model.SearchID `validate:"required"`
+	Title            *string        `validate:"omitempty,lte=100"`
+	Query            *string        `validate:"omitempty,gte=3,lte=100"`
+	IntervalMinutes  *int           `validate:"omitempty,gte=5,lte=1440"`
+	AdaptiveInterval *bool
+	// SentimentDetector changes the detector of tweets collected after the update. Empty means the configured default detector.
+	SentimentDetector *string `validate:"omitempty,oneof=bayes comprehend lexicon ensemble"`
+	FilterRules       *FilterRulesInput
+}
+
+// UpdateUserSearch updates the search of the authenticated user.
+// When the query is changed, tweets collected by the previous query are kept,
+// and the new query is collected by the next run as soon as possible, and its past tweets are backfilled.
+func (u *searchUsecase) UpdateUserSearch(ctx context.Context, input *SearchUsecaseUpdateInput) (*model.Search, error) {
+	err := u.validator.StructCtx(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if input.Query != nil {
+		query, err := normalizeSearchQuery(*input.Query)
+		if err != nil {
+			return nil, err
+		}
+		input.Query = &query
+	}
+
+	userID, err := u.authenticator.UserID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching user id error: %w", err)
+	}
+
+	search, err := u.searchRepository.Find(ctx, userID, input.SearchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch search (id: %v): %w", input.SearchID, err)
+	}
+	if search.Status == model.SearchStatusDeleting {
+		return nil, ErrSearchDeleting
+	}
+
+	now := time.Now()
+	previousQuery := search.Query
+	scheduleChanged := false
+	if input.Title != nil {
+		search.Title = *input.Title
+	}
+	adaptiveChanged := input.AdaptiveInterval != nil && *input.AdaptiveInterval != search.AdaptiveInterval
+	if adaptiveChanged {
+		search.AdaptiveInterval = *input.AdaptiveInterval
+	}
+	if input.FilterRules != nil {
+		search.FilterRules = input.FilterRules.toModel()
+	}
+	if input.SentimentDetector != nil {
+		search.SentimentDetector = sentiment.DetectorName(*input.SentimentDetector)
+	}
+	intervalChanged := input.IntervalMinutes != nil && *input.IntervalMinutes != search.IntervalMinutes
+	if intervalChanged {
+		quota, err := u.quota.findQuota(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if err = u.quota.checkInterval(*input.IntervalMinutes, quota); err != nil {
+			return nil, err
+		}
+		search.IntervalMinutes = *input.IntervalMinutes
+	}
+	if adaptiveChanged || intervalChanged {
+		// The interval adjusted by the previous settings is discarded, and the adaptive scheduling restarts from the interval.
+		search.EffectiveIntervalMinutes = int(search.Interval() / time.Minute)
+		lastUpdatedAt := now
+		if search.LastSearchUpdatedAt != nil {
+			lastUpdatedAt = *search.LastSearchUpdatedAt
+		}
+		search.NextSearchUpdateAt = lastUpdatedAt.Add(search.Interval())
+		search.NextSearchUpdateReason = fmt.Sprintf("interval was changed to %d minutes", search.Interval()/time.Minute)
+		if !intervalChanged {
+			search.NextSearchUpdateReason = fmt.Sprintf("adaptive interval was changed, interval was reset to %d minutes", search.Interval()/time.Minute)
+		}
+		scheduleChanged = true
+	}
+	queryChanged := input.Query != nil && *input.Query != search.Query
+	if queryChanged {
+		sinceTweetID := model.TweetID(twitter.MinTweetIDAt(now))
+		search.Query = *input.Query
+		search.SinceTweetID = &sinceTweetID
+		search.CollectionCheckpoint = nil
+		search.Backfill = model.NewSearchBackfill(now)
+		search.LastSearchUpdatedAt = nil
+		search.NextSearchUpdateAt = now
+		search.NextSearchUpdateReason = "query was changed"
+	}
+	search.UpdatedAt = now
+
+	// Only the changed fields are written, so that the progress saved by a running collection or backfill is kept.
+	if queryChanged {
+		err = u.searchRepository.UpdateQuery(ctx, search, previousQuery)
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("search was changed by others (id: %v): %w", search.SearchID, err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("updating search query error: %w", err)
+		}
+
+		u.dispatchBackfill(ctx, search)
+		return search, nil
+	}
+
+	err = u.searchRepository.UpdateSettings(ctx, search)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("search was already deleted (id: %v): %w", search.SearchID, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("updating search error: %w", err)
+	}
+	if scheduleChanged {
+		err = u.searchRepository.UpdateSchedule(ctx, search)
+		if err != nil && !errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("updating search schedule error: %w", err)
+		}
+	}
+
 	return search, nil
 }
 
+// ChangeUserSearchStatus changes the status of the search of the authenticated user.
+// Searches which are not active are not collected until they are resumed.
+func (u *searchUsecase) ChangeUserSearchStatus(ctx context.Context, searchID model.SearchID, status model.SearchStatus) (*model.Search, error) {
+	userID, err := u.authenticator.UserID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching user id error: %w", err)
+	}
+
+	search, err := u.searchRepository.Find(ctx, userID, searchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch search (id: %v): %w", searchID, err)
+	}
+	if search.Status == model.SearchStatusDeleting {
+		return nil, ErrSearchDeleting
+	}
+	if status == model.SearchStatusActive && !search.IsActive() {
+		quota, err := u.quota.findQuota(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if err = u.quota.checkActiveSearches(ctx, userID, quota); err != nil {
+			return nil, err
+		}
+	}
+
+	// Only the status is written, so that the progress of a running collection or backfill is kept.
+	err = u.searchRepository.UpdateStatus(ctx, search, status, "")
+	if errors.Is(err, repository.ErrConflict) {
+		return nil, fmt.Errorf("search status was changed by others (id: %v): %w", searchID, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("updating search status error: %w", err)
+	}
+
+	return search, nil
+}
+
+// UpdateNextUpdateAt reserves the next collection by the current interval before collecting tweets,
+// so that the search is not collected concurrently, and is retried later if the collection fails.
 func (u *searchUsecase) UpdateNextUpdateAt(ctx context.Context, search *model.Search) error {
 	now := time.Now()
 	search.LastSearchUpdatedAt = &now
-	search.NextSearchUpdateAt = time.Now().Add(30 * time.Minute)
-	err := u.searchRepository.Update(ctx, search)
+	search.NextSearchUpdateAt = now.Add(search.EffectiveInterval())
+	search.NextSearchUpdateReason = fmt.Sprintf("collection started, retried after %d minutes if it fails", search.EffectiveInterval()/time.Minute)
+	err := u.searchRepository.UpdateSchedule(ctx, search)
 
+	if errors.Is(err, repository.ErrConflict) {
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update search next updated at: %w", err)
 	}
 
 	return nil
 }
+
+// ScheduleNextUpdate schedules the next collection after collecting tweets.
+// The interval is not shorter than the minimum interval of the quota of the user.
+// It does nothing if the query of the search was changed while collecting tweets.
+func (u *searchUsecase) ScheduleNextUpdate(ctx context.Context, search *model.Search, quota model.Quota, foundTweets int) error {
+	lastUpdatedAt := time.Now()
+	if search.LastSearchUpdatedAt != nil {
+		lastUpdatedAt = *search.LastSearchUpdatedAt
+	}
+	scheduleNextUpdate(search, lastUpdatedAt, foundTweets, quota.MinInterval())
+	err := u.searchRepository.UpdateSchedule(ctx, search)
+
+	if errors.Is(err, repository.ErrConflict) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to schedule next search update: %w", err)
+	}
+
+	return nil
+}
+
+// PostponeUserSearches postpones the next collections of the active searches of the user until the given time,
+// e.g. when the rate limit of the user's token is exceeded. Searches which are scheduled later are not changed.
+func (u *searchUsecase) PostponeUserSearches(ctx context.Context, userID model.UserID, until time.Time, reason string) error {
+	searches, err := u.searchRepository.ListByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list searches of user (id: %s): %w", userID, err)
+	}
+
+	for _, search := range searches {
+		if !search.IsActive() || !search.NextSearchUpdateAt.Before(until) {
+			continue
+		}
+
+		search.NextSearchUpdateAt = until
+		search.NextSearchUpdateReason = reason
+		err := u.searchRepository.UpdateSchedule(ctx, search)
+		if errors.Is(err, repository.ErrConflict) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to postpone search (id: %s): %w", search.SearchID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hareku/emosearch-api/pkg/domain/job"
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+)
+
+// recordingSearchRepository records the partial updates of the search. Full updates are not implemented.
+type recordingSearchRepository struct {
+	stubSearchRepository
+	calls         []string
+	previousQuery string
+}
+
+func (r *recordingSearchRepository) UpdateSettings(ctx context.Context, search *model.Search) error {
+	r.calls = append(r.calls, "UpdateSettings")
+	return nil
+}
+
+func (r *recordingSearchRepository) UpdateQuery(ctx context.Context, search *model.Search, previousQuery string) error {
+	r.calls = append(r.calls, "UpdateQuery")
+	r.previousQuery = previousQuery
+	return nil
+}
+
+func (r *recordingSearchRepository) UpdateSchedule(ctx context.Context, search *model.Search) error {
+	r.calls = append(r.calls, "UpdateSchedule")
+	return nil
+}
+
+type stubDispatcher struct {
+	job.Dispatcher
+	backfills int
+}
+
+func (d *stubDispatcher) DispatchSearchBackfill(ctx context.Context, searchID model.SearchID, userID model.UserID) error {
+	d.backfills++
+	return nil
+}
+
+func Test_searchUsecase_UpdateUserSearch(t *testing.T) {
+	title, query, adaptive := "new title", "new query", false
+	sinceTweetID := model.TweetID(100)
+
+	tests := []struct {
+		name          string
+		input         SearchUsecaseUpdateInput
+		wantCalls     []string
+		wantBackfills int
+		wantEffective int
+	}{
+		{
+			name:          "title",
+			input:         SearchUsecaseUpdateInput{SearchID: "search", Title: &title},
+			wantCalls:     []string{"UpdateSettings"},
+			wantEffective: 240,
+		},
+		{
+			name:          "query",
+			input:         SearchUsecaseUpdateInput{SearchID: "search", Title: &title, Query: &query},
+			wantCalls:     []string{"UpdateQuery"},
+			wantBackfills: 1,
+			wantEffective: 240,
+		},
+		{
+			name:          "adaptive interval resets the adjusted interval",
+			input:         SearchUsecaseUpdateInput{SearchID: "search", Title: &title, AdaptiveInterval: &adaptive},
+			wantCalls:     []string{"UpdateSettings", "UpdateSchedule"},
+			wantEffective: 60,
+		},
+	}
+
+	for _, tt := range tests {
+		searchRepository := &recordingSearchRepository{stubSearchRepository: stubSearchRepository{search: &model.Search{
+			UserID:                   "user",
+			SearchID:                 "search",
+			Title:                    "title",
+			Query:                    "query",
+			SinceTweetID:             &sinceTweetID,
+			IntervalMinutes:          60,
+			AdaptiveInterval:         true,
+			EffectiveIntervalMinutes: 240,
+		}}}
+		dispatcher := &stubDispatcher{}
+		u := NewSearchUsecase(&NewSearchUsecaseInput{
+			Authenticator:    &stubAuthenticator{userID: "user"},
+			Validator:        &stubValidator{},
+			SearchRepository: searchRepository,
+			JobDispatcher:    dispatcher,
+		})
+
+		search, err := u.UpdateUserSearch(context.Background(), &tt.input)
+		if err != nil {
+			t.Fatalf("%s: UpdateUserSearch() returned error: %s", tt.name, err)
+		}
+		if search.Title != title {
+			t.Errorf("%s: Title = %q, want %q", tt.name, search.Title, title)
+		}
+		if !reflect.DeepEqual(searchRepository.calls, tt.wantCalls) {
+			t.Errorf("%s: repository calls = %v, want %v", tt.name, searchRepository.calls, tt.wantCalls)
+		}
+		if search.EffectiveIntervalMinutes != tt.wantEffective {
+			t.Errorf("%s: EffectiveIntervalMinutes = %d, want %d", tt.name, search.EffectiveIntervalMinutes, tt.wantEffective)
+		}
+		if dispatcher.backfills != tt.wantBackfills {
+			t.Errorf("%s: dispatched %d backfills, want %d", tt.name, dispatcher.backfills, tt.wantBackfills)
+		}
+		if tt.input.Query != nil && (searchRepository.previousQuery != "query" || *search.SinceTweetID == sinceTweetID) {
+			t.Errorf("%s: query was updated from %q with cursor %d, want from \"query\" with a new cursor", tt.name, searchRepository.previousQuery, *search.SinceTweetID)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hareku/emosearch-api/pkg/domain/auth"
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+	"github.com/hareku/emosearch-api/pkg/domain/repository"
+	"github.com/hareku/emosearch-api/pkg/domain/twitter"
+	"github.com/hareku/emosearch-api/pkg/domain/validator"
+)
+
+var (
+	// ErrTooManyTimelineBuckets is returned when the requested range of sentiment timeline is too wide.
+	ErrTooManyTimelineBuckets = errors.New("too many timeline buckets")
+
+	// ErrTooManyTimelineTweets is returned when the requested range of hourly sentiment timeline has too many tweets.
+	ErrTooManyTimelineTweets = errors.New("too many tweets in timeline")
+)
+
+const (
+	// maxTimelineBuckets is the maximum number of buckets in a sentiment timeline (31 days of hours).
+	maxTimelineBuckets = 744
+
+	// maxTimelineTweets is the maximum number of tweets which are read for an hourly sentiment timeline.
+	maxTimelineTweets = 50000
+)
+
+// TweetUsecase provides usecases of Tweet domain.
+type TweetUsecase interface {
+	SentimentTimeline(ctx context.Context, input *TweetUsecaseSentimentTimelineInput) ([]model.SentimentTimelineBucket, error)
+}
+
+type tweetUsecase struct {
+	authenticator    auth.Authenticator
+	validator        validator.Validator
+	searchRepository repository.SearchRepository
+	tweetRepository  repository.TweetRepository
+}
+
+// NewTweetUsecase creates TweetUsecase.
+func NewTweetUsecase(authenticator auth.Authenticator, validator validator.Validator, searchRepository repository.SearchRepository, tweetRepository repository.TweetRepository) TweetUsecase {
+	return &tweetUsecase{authenticator, validator, searchRepository, tweetRepository}
+}
+
+// TweetUsecaseSentimentTimelineInput is the input of TweetUsecase.SentimentTimeline().
+// From defaults to a day (hour bucket) or 30 days (day bucket) before To, and To defaults to now.
+// Day buckets are UTC days which have only the counts of labels, see TweetRepositoryAggregateSentimentTimelineInput.
+type TweetUsecaseSentimentTimelineInput struct {
+	SearchID model.SearchID       `validate:"required"`
+	Bucket   model.TimelineBucket `validate:"required,oneof=hour day"`
+	From     time.Time
+	To       time.Time `validate:"gtfield=From"`
+}
+
+func (u *tweetUsecase) SentimentTimeline(ctx context.Context, input *TweetUsecaseSentimentTimelineInput) ([]model.SentimentTimelineBucket, error) {
+	if input.To.IsZero() {
+		input.To = time.Now()
+	}
+	if input.From.IsZero() {
+		if input.Bucket == model.TimelineBucketDay {
+			input.From = input.To.AddDate(0, 0, -30)
+		} else {
+			input.From = input.To.Add(-24 * time.Hour)
+		}
+	}
+
+	err := u.validator.StructCtx(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.To.Sub(input.From) > maxTimelineBuckets*input.Bucket.Duration() {
+		return nil, ErrTooManyTimelineBuckets
+	}
+
+	userID, err := u.authenticator.UserID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user id: %w", err)
+	}
+
+	search, err := u.searchRepository.Find(ctx, userID, input.SearchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch search (id: %v): %w", input.SearchID, err)
+	}
+
+	// The search has no tweets older than the search window before its creation,
+	// and tweet IDs of the range must be issued after the Snowflake epoch to be compared as sort keys.
+	from := input.From
+	if earliest := search.CreatedAt.Add(-twitter.SearchWindow); from.Before(earliest) {
+		from = earliest.In(from.Location())
+	}
+	if !from.Before(input.To) {
+		return []model.SentimentTimelineBucket{}, nil
+	}
+
+	buckets, err := u.tweetRepository.AggregateSentimentTimeline(ctx, &repository.TweetRepositoryAggregateSentimentTimelineInput{
+		SearchID:  input.SearchID,
+		Bucket:    input.Bucket,
+		From:      from,
+		To:        input.To,
+		MaxTweets: maxTimelineTweets,
+	})
+	if errors.Is(err, repository.ErrTooManyItems) {
+		return nil, ErrTooManyTimelineTweets
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate sentiment timeline: %w", err)
+	}
+
+	return buckets, nil
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hareku/emosearch-api/pkg/domain/auth"
+	"github.com/hareku/emosearch-api/pkg/domain/model"
+	"github.com/hareku/emosearch-api/pkg/domain/repository"
+	"github.com/hareku/emosearch-api/pkg/domain/twitter"
+)
+
+type stubValidator struct{}
+
+func (v *stubValidator) StructCtx(ctx context.Context, s interface{}) error {
+	return nil
+}
+
+type stubAuthenticator struct {
+	auth.Authenticator
+	userID model.UserID
+}
+
+func (a *stubAuthenticator) UserID(ctx context.Context) (model.UserID, error) {
+	return a.userID, nil
+}
+
+type stubSearchRepository struct {
+	repository.SearchRepository
+	search *model.Search
+}
+
+func (r *stubSearchRepository) Find(ctx context.Context, userID model.UserID, searchID model.SearchID) (*model.Search, error) {
+	if r.search == nil || r.search.UserID != userID || r.search.SearchID != searchID {
+		return nil, repository.ErrNotFound
+	}
+	return r.search, nil
+}
+
+// stubTweetRepository aggregates no tweets, and records the input of the aggregation.
+type stubTweetRepository struct {
+	repository.TweetRepository
+	input *repository.TweetRepositoryAggregateSentimentTimelineInput
+}
+
+func (r *stubTweetRepository) AggregateSentimentTimeline(ctx context.Context, input *repository.TweetRepositoryAggregateSentimentTimelineInput) ([]model.SentimentTimelineBucket, error) {
+	r.input = input
+	return model.NewSentimentTimeline(input.Bucket, input.From, input.To).Buckets(), nil
+}
+
+func newStubTweetUsecase() (TweetUsecase, *stubTweetRepository) {
+	search := &model.Search{UserID: "user", SearchID: "search", CreatedAt: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)}
+	tweetRepository := &stubTweetRepository{}
+	u := NewTweetUsecase(&stubAuthenticator{userID: "user"}, &stubValidator{}, &stubSearchRepository{search: search}, tweetRepository)
+	return u, tweetRepository
+}
+
+func Test_tweetUsecase_SentimentTimeline(t *testing.T) {
+	to := time.Date(2021, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		bucket      model.TimelineBucket
+		from        time.Time
+		wantBuckets int
+		wantErr     error
+	}{
+		{"maximum hours", model.TimelineBucketHour, to.Add(-maxTimelineBuckets * time.Hour), maxTimelineBuckets, nil},
+		{"too many hours", model.TimelineBucketHour, to.Add(-(maxTimelineBuckets + 1) * time.Hour), 0, ErrTooManyTimelineBuckets},
+		{"maximum days", model.TimelineBucketDay, to.AddDate(0, 0, -maxTimelineBuckets), maxTimelineBuckets, nil},
+		{"too many days", model.TimelineBucketDay, to.AddDate(0, 0, -maxTimelineBuckets-1), 0, ErrTooManyTimelineBuckets},
+	}
+
+	for _, tt := range tests {
+		u, _ := newStubTweetUsecase()
+		buckets, err := u.SentimentTimeline(context.Background(), &TweetUsecaseSentimentTimelineInput{
+			SearchID: "search",
+			Bucket:   tt.bucket,
+			From:     tt.from,
+			To:       to,
+		})
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: SentimentTimeline() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if len(buckets) != tt.wantBuckets {
+			t.Errorf("%s: SentimentTimeline() returned %d buckets, want %d", tt.name, len(buckets), tt.wantBuckets)
+		}
+	}
+}
+
+func Test_tweetUsecase_SentimentTimeline_DefaultRange(t *testing.T) {
+	to := time.Date(2021, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		bucket      model.TimelineBucket
+		wantFrom    time.Time
+		wantBuckets int
+	}{
+		{model.TimelineBucketHour, to.Add(-24 * time.Hour), 24},
+		{model.TimelineBucketDay, to.AddDate(0, 0, -30), 30},
+	}
+
+	for _, tt := range tests {
+		u, tweetRepository := newStubTweetUsecase()
+		buckets, err := u.SentimentTimeline(context.Background(), &TweetUsecaseSentimentTimelineInput{SearchID: "search", Bucket: tt.bucket, To: to})
+		if err != nil {
+			t.Fatalf("SentimentTimeline() returned error: %s", err)
+		}
+
+		want := &repository.TweetRepositoryAggregateSentimentTimelineInput{SearchID: "search", Bucket: tt.bucket, From: tt.wantFrom, To: to, MaxTweets: maxTimelineTweets}
+		if !reflect.DeepEqual(tweetRepository.input, want) {
+			t.Errorf("aggregation input = %+v, want %+v", tweetRepository.input, want)
+		}
+		if len(buckets) != tt.wantBuckets || !buckets[0].StartAt.Equal(tt.wantFrom) {
+			t.Errorf("%s buckets: got %d buckets from %v, want %d from %v", tt.bucket, len(buckets), buckets[0].StartAt, tt.wantBuckets, tt.wantFrom)
+		}
+	}
+}
+
+func Test_tweetUsecase_SentimentTimeline_BeforeSearch(t *testing.T) {
+	createdAt := time.Date(2021, 1, 10, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2021, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		from        time.Time
+		to          time.Time
+		wantFrom    time.Time
+		wantBuckets int
+	}{
+		// Tweets of the search window before the creation can be collected by the backfill.
+		{"from is clamped", time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC), to, createdAt.Add(-twitter.SearchWindow), 28},
+		{"range ends before the search", time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC), time.Time{}, 0},
+	}
+
+	for _, tt := range tests {
+		tweetRepository := &stubTweetRepository{}
+		search := &model.Search{UserID: "user", SearchID: "search", CreatedAt: createdAt}
+		u := NewTweetUsecase(&stubAuthenticator{userID: "user"}, &stubValidator{}, &stubSearchRepository{search: search}, tweetRepository)
+
+		buckets, err := u.SentimentTimeline(context.Background(), &TweetUsecaseSentimentTimelineInput{
+			SearchID: "search",
+			Bucket:   model.TimelineBucketDay,
+			From:     tt.from,
+			To:       tt.to,
+		})
+		if err != nil {
+			t.Fatalf("%s: SentimentTimeline() returned error: %s", tt.name, err)
+		}
+		if len(buckets) != tt.wantBuckets {
+			t.Errorf("%s: SentimentTimeline() returned %d buckets, want %d", tt.name, len(buckets), tt.wantBuckets)
+		}
+		if tt.wantFrom.IsZero() {
+			if tweetRepository.input != nil {
+				t.Errorf("%s: tweets were aggregated from %v", tt.name, tweetRepository.input.From)
+			}
+		} else if tweetRepository.input == nil || !tweetRepository.input.From.Equal(tt.wantFrom) {
+			t.Errorf("%s: aggregation input = %+v, want from %v", tt.name, tweetRepository.input, tt.wantFrom)
+		}
+	}
+}
+
+func Test_tweetUsecase_SentimentTimeline_NotFound(t *testing.T) {
+	u, _ := newStubTweetUsecase()
+	_, err := u.SentimentTimeline(context.Background(), &TweetUsecaseSentimentTimelineInput{SearchID: "other", Bucket: model.TimelineBucketHour})
+	if !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("SentimentTimeline() error = %v, want ErrNotFound", err)
+	}
+}
@@ -4,15 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/hareku/emosearch-api/pkg/domain/auth"
 	"github.com/hareku/emosearch-api/pkg/domain/model"
 	"github.com/hareku/emosearch-api/pkg/domain/repository"
+	"github.com/hareku/emosearch-api/pkg/domain/validator"
 )
 
 var (
 	// ErrUserAlreadyExist is returned when user already exist in registration.
 	ErrUserAlreadyExist = errors.New("user already exist")
+
+	// ErrNotAdmin is returned when the authenticated user is not an admin.
+	ErrNotAdmin = errors.New("admin permission is required")
 )
 
 // UserUsecase provides usecases of User domain.
@@ -20,18 +25,27 @@ type UserUsecase interface {
 	FetchAuthUser(ctx context.Context) (*model.User, error)
 	FindByID(ctx context.Context, userID model.UserID) (*model.User, error)
 	Register(ctx context.Context, input UserUsecaseRegisterInput) (*model.User, error)
+	GetAuthUserQuota(ctx context.Context) (*UserQuotaStatus, error)
+	UpdateUserQuota(ctx context.Context, input *UserUsecaseUpdateQuotaInput) (*model.User, error)
+	ResetUserQuota(ctx context.Context, userID model.UserID) (*model.User, error)
 }
 
 type userUsecase struct {
-	authenticator  auth.Authenticator
-	userRepository repository.UserRepository
+	authenticator       auth.Authenticator
+	validator           validator.Validator
+	userRepository      repository.UserRepository
+	userUsageRepository repository.UserUsageRepository
+	reauth              *reauthorizer
 }
 
 // NewUserUsecase creates UserUsecase.
-func NewUserUsecase(authenticator auth.Authenticator, userRepository repository.UserRepository) UserUsecase {
+func NewUserUsecase(authenticator auth.Authenticator, validator validator.Validator, userRepository repository.UserRepository, userUsageRepository repository.UserUsageRepository, searchRepository repository.SearchRepository) UserUsecase {
 	return &userUsecase{
 		authenticator,
+		validator,
 		userRepository,
+		userUsageRepository,
+		&reauthorizer{userRepository, searchRepository},
 	}
 }
 
@@ -64,6 +78,8 @@ type UserUsecaseRegisterInput struct {
 	TwitterAccessTokenSecret string
 }
 
+// Register registers the authenticated user with the Twitter token.
+// If the user needs reauthorization, the token is replaced and the searches paused by the revoked token are resumed.
 func (u *userUsecase) Register(ctx context.Context, input UserUsecaseRegisterInput) (*model.User, error) {
 	userID, err := u.authenticator.UserID(ctx)
 	if err != nil {
@@ -74,6 +90,13 @@ func (u *userUsecase) Register(ctx context.Context, input UserUsecaseRegisterInp
 	if err != nil {
 		return nil, fmt.Errorf("fetching user error: %w", err)
 	}
+	if user != nil && user.NeedsReauthorization() {
+		err = u.reauth.reauthorize(ctx, user, input.TwitterAccessToken, input.TwitterAccessTokenSecret)
+		if err != nil {
+			return nil, fmt.Errorf("user reauthorization error: %w", err)
+		}
+		return user, nil
+	}
 	if user != nil {
 		return user, ErrUserAlreadyExist
 	}
@@ -91,3 +114,85 @@ func (u *userUsecase) Register(ctx context.Context, input UserUsecaseRegisterInp
 
 	return user, nil
 }
+
+// UserQuotaStatus is the quota of a user and the usage in this month.
+type UserQuotaStatus struct {
+	Quota model.Quota
+	Usage *model.UserUsage
+}
+
+func (u *userUsecase) GetAuthUserQuota(ctx context.Context) (*UserQuotaStatus, error) {
+	userID, err := u.authenticator.UserID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get user id: %w", err)
+	}
+
+	user, err := u.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get user from repository: %w", err)
+	}
+
+	usage, err := u.userUsageRepository.FindMonthly(ctx, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("could not get user usage: %w", err)
+	}
+
+	return &UserQuotaStatus{
+		Quota: user.CurrentQuota(),
+		Usage: usage,
+	}, nil
+}
+
+// UserUsecaseUpdateQuotaInput is the input of UserUsecase.UpdateUserQuota().
+type UserUsecaseUpdateQuotaInput struct {
+	UserID             model.UserID `validate:"required"`
+	MaxActiveSearches  int          `validate:"gte=0,lte=1000"`
+	MaxTweetsPerMonth  int64        `validate:"gte=0"`
+	MinIntervalMinutes int          `validate:"gte=5,lte=1440"`
+}
+
+// UpdateUserQuota configures the quota of the user. Only admins can call it.
+func (u *userUsecase) UpdateUserQuota(ctx context.Context, input *UserUsecaseUpdateQuotaInput) (*model.User, error) {
+	if !u.authenticator.IsAdmin(ctx) {
+		return nil, ErrNotAdmin
+	}
+
+	err := u.validator.StructCtx(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	quota := &model.Quota{
+		MaxActiveSearches:  input.MaxActiveSearches,
+		MaxTweetsPerMonth:  input.MaxTweetsPerMonth,
+		MinIntervalMinutes: input.MinIntervalMinutes,
+	}
+
+	return u.updateQuota(ctx, input.UserID, quota)
+}
+
+// ResetUserQuota restores the default quota of the user. Only admins can call it.
+func (u *userUsecase) ResetUserQuota(ctx context.Context, userID model.UserID) (*model.User, error) {
+	if !u.authenticator.IsAdmin(ctx) {
+		return nil, ErrNotAdmin
+	}
+
+	return u.updateQuota(ctx, userID, nil)
+}
+
+func (u *userUsecase) updateQuota(ctx context.Context, userID model.UserID, quota *model.Quota) (*model.User, error) {
+	err := u.userRepository.UpdateQuota(ctx, userID, quota)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("user was not found (id: %v): %w", userID, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("updating user quota error: %w", err)
+	}
+
+	user, err := u.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get user: %w", err)
+	}
+
+	return user, nil
+}